@@ -2,16 +2,17 @@ package api
 
 import (
 	"database/sql"
-	"log"
+	"errors"
 	"net/http"
 	db "simplebank/db/sqlc"
-	"strconv"
+	"simplebank/token"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
 type createAccountRequest struct {
-	Owner    string `json:"owner" binding:"required"`
 	Currency string `json:"currency" binding:"required,currency"`
 }
 
@@ -23,15 +24,24 @@ func (serv *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.CreateAccountParams{
-		Owner:    req.Owner,
+		Owner:    authPayload.Username,
 		Currency: req.Currency,
 		Balance:  0,
 	}
 
-	acc, err := serv.store.CreateAccount(ctx, arg)
+	acc, err := serv.store.CreateAccountTx(ctx, arg)
 
 	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation", "unique_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -51,14 +61,8 @@ func (serv *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	acc, err := serv.store.GetAccount(ctx, req.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	acc, ok := serv.ownedAccount(ctx, req.ID)
+	if !ok {
 		return
 	}
 
@@ -78,7 +82,9 @@ func (serv *Server) listAccounts(ctx *gin.Context) {
 		return
 	}
 
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.ListAccountsParams{
+		Owner:  authPayload.Username,
 		Limit:  req.PageSize,
 		Offset: (req.PageID - 1) * req.PageSize,
 	}
@@ -92,29 +98,148 @@ func (serv *Server) listAccounts(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, acc)
 }
 
-type updateAccountBalance struct {
-		Amount int64 `json:"amount" binding:"required"`
+type getAccountBalanceRequest struct {
+	// AsOf is an RFC 3339 time, entries from then on are left out. Empty means now.
+	AsOf time.Time `form:"as_of"`
 }
 
-func (serv *Server) updateAccountBalance(ctx *gin.Context) {
-	var req updateAccountBalance
-	id, _ := strconv.ParseInt(ctx.Params.ByName("id"), 10, 64)
+type accountBalanceResponse struct {
+	AccountID int64     `json:"account_id"`
+	Currency  string    `json:"currency"`
+	AsOf      time.Time `json:"as_of"`
+	Balance   int64     `json:"balance"`
+}
 
-	log.Println(id)
+// getAccountBalance returns the balance of an account as of a time, summed from its entries
+func (serv *Server) getAccountBalance(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	var req getAccountBalanceRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	acc, err := serv.store.UpdateAccountBalance(ctx, db.UpdateAccountBalanceParams{
-		Amount: req.Amount,
-		ID:    id,
-	})
+	now := time.Now()
+	if req.AsOf.IsZero() {
+		req.AsOf = now
+	}
+	// entries are dated in UTC
+	req.AsOf = req.AsOf.UTC()
 
+	if req.AsOf.After(now) {
+		err := errors.New("as_of must not be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	acc, ok := serv.ownedAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	balance, err := serv.store.GetBalanceAt(ctx, db.GetBalanceAtParams{
+		AccountID: acc.ID,
+		AsOf:      req.AsOf,
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accountBalanceResponse{
+		AccountID: acc.ID,
+		Currency:  acc.Currency,
+		AsOf:      req.AsOf,
+		Balance:   balance.Balance,
+	})
+}
+
+func (serv *Server) freezeAccount(ctx *gin.Context) {
+	serv.changeAccountStatus(ctx, db.AccountStatusFrozen)
+}
+
+func (serv *Server) unfreezeAccount(ctx *gin.Context) {
+	serv.changeAccountStatus(ctx, db.AccountStatusActive)
+}
+
+func (serv *Server) closeAccount(ctx *gin.Context) {
+	serv.changeAccountStatus(ctx, db.AccountStatusClosed)
+}
+
+func (serv *Server) changeAccountStatus(ctx *gin.Context, status string) {
+	var req getAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// operators can act on any account, unfreezing is left to them alone
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if serv.isOperator(authPayload.Username) {
+		if _, ok := serv.existingAccount(ctx, req.ID); !ok {
+			return
+		}
+	} else if _, ok := serv.ownedAccount(ctx, req.ID); !ok {
+		return
+	}
+
+	acc, err := serv.store.ChangeAccountStatusTx(ctx, db.ChangeAccountStatusTxParams{
+		AccountID: req.ID,
+		Status:    status,
+	})
+	if err != nil {
+		var notEmptyErr *db.AccountNotEmptyError
+		var inUseErr *db.AccountInUseError
+		switch {
+		case errors.As(err, &notEmptyErr):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   err.Error(),
+				"balance": notEmptyErr.Balance,
+			})
+		case errors.As(err, &inUseErr):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":               err.Error(),
+				"held_amount":         inUseErr.HeldAmount,
+				"pending_holds":       inUseErr.PendingHolds,
+				"scheduled_transfers": inUseErr.ScheduledTransfers,
+			})
+		case errors.Is(err, db.ErrInvalidStatusTransition):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
 	}
 
 	ctx.JSON(http.StatusOK, acc)
 }
+
+// ownedAccount fetches the account and checks that it belongs to the authenticated user.
+// On failure the error response is already written and false is returned.
+func (serv *Server) ownedAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	acc, err := serv.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return acc, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return acc, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if acc.Owner != authPayload.Username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return acc, false
+	}
+
+	return acc, true
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createAdjustmentRequest struct {
+	Kind string `json:"kind" binding:"required,oneof=deposit withdrawal correction"`
+	// Amount is positive for deposits and withdrawals, signed for corrections
+	Amount     int64  `json:"amount" binding:"required"`
+	ReasonCode string `json:"reason_code" binding:"required"`
+	Note       string `json:"note" binding:"max=255"`
+}
+
+// createAdjustment deposits to, withdraws from or corrects the balance of any account,
+// balanced by the suspense account of its currency. Only operators get through to it.
+func (server *Server) createAdjustment(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req createAdjustmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.existingAccount(ctx, uri.ID); !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	res, err := server.store.AdjustAccountTx(ctx, db.AdjustAccountTxParams{
+		AccountID:  uri.ID,
+		Kind:       req.Kind,
+		Amount:     req.Amount,
+		ReasonCode: req.ReasonCode,
+		Note:       req.Note,
+		CreatedBy:  authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidAdjustment) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		// a withdrawal fails like the debit side of a transfer
+		transferErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, res)
+}
+
+type listAdjustmentsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=15"`
+}
+
+// listAdjustments lists the adjustments of an account, newest first
+func (server *Server) listAdjustments(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listAdjustmentsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, uri.ID); !ok {
+		return
+	}
+
+	adjustments, err := server.store.ListAdjustments(ctx, db.ListAdjustmentsParams{
+		AccountID: uri.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, adjustments)
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAdjustmentAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	operator, _ := randomUser(t)
+	acc := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		username      string
+		accountID     int64
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			username:  operator.Username,
+			accountID: acc.ID,
+			body: gin.H{
+				"kind":        db.AdjustmentDeposit,
+				"amount":      50,
+				"reason_code": "cash",
+				"note":        "counter deposit",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(acc.ID)).
+					Times(1).
+					Return(acc, nil)
+				store.EXPECT().
+					AdjustAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.AdjustAccountTxParams) (db.AdjustAccountTxResult, error) {
+						require.Equal(t, db.AdjustAccountTxParams{
+							AccountID:  acc.ID,
+							Kind:       db.AdjustmentDeposit,
+							Amount:     50,
+							ReasonCode: "cash",
+							Note:       "counter deposit",
+							CreatedBy:  operator.Username,
+						}, arg)
+
+						return db.AdjustAccountTxResult{
+							Adjustment: db.Adjustment{ID: 1, AccountID: acc.ID, Kind: arg.Kind, Amount: arg.Amount},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res db.AdjustAccountTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, int64(1), res.Adjustment.ID)
+			},
+		},
+		{
+			name:      "InvalidKind",
+			username:  operator.Username,
+			accountID: acc.ID,
+			body: gin.H{
+				"kind":        "refund",
+				"amount":      50,
+				"reason_code": "cash",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			username:  operator.Username,
+			accountID: 0,
+			body: gin.H{
+				"kind":        db.AdjustmentDeposit,
+				"amount":      50,
+				"reason_code": "cash",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AdjustAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "AccountOwner",
+			username:  user.Username,
+			accountID: acc.ID,
+			body: gin.H{
+				"kind":        db.AdjustmentDeposit,
+				"amount":      50,
+				"reason_code": "cash",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AdjustAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "AccountNotFound",
+			username:  operator.Username,
+			accountID: acc.ID,
+			body: gin.H{
+				"kind":        db.AdjustmentDeposit,
+				"amount":      50,
+				"reason_code": "cash",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(acc.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().AdjustAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "WrongReasonCode",
+			username:  operator.Username,
+			accountID: acc.ID,
+			body: gin.H{
+				"kind":        db.AdjustmentCorrection,
+				"amount":      -5,
+				"reason_code": "cash",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(acc.ID)).
+					Times(1).
+					Return(acc, nil)
+				store.EXPECT().
+					AdjustAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdjustAccountTxResult{}, fmt.Errorf("%w: reason code", db.ErrInvalidAdjustment))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InsufficientFunds",
+			username:  operator.Username,
+			accountID: acc.ID,
+			body: gin.H{
+				"kind":        db.AdjustmentWithdrawal,
+				"amount":      acc.Balance + 1,
+				"reason_code": "wire",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(acc.ID)).
+					Times(1).
+					Return(acc, nil)
+				store.EXPECT().
+					AdjustAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdjustAccountTxResult{}, &db.InsufficientFundsError{
+						AccountID: acc.ID,
+						Available: acc.Balance,
+						Requested: acc.Balance + 1,
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "available_balance")
+			},
+		},
+		{
+			name:      "NoSuspenseAccount",
+			username:  operator.Username,
+			accountID: acc.ID,
+			body: gin.H{
+				"kind":        db.AdjustmentDeposit,
+				"amount":      50,
+				"reason_code": "cash",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(acc.ID)).
+					Times(1).
+					Return(acc, nil)
+				store.EXPECT().
+					AdjustAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdjustAccountTxResult{}, db.ErrNoSuspenseAccount)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.Operators = []string{operator.Username}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/adjustments", tc.accountID)
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
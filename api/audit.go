@@ -0,0 +1,75 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type listAuditLogRequest struct {
+	Actor      string    `form:"actor"`
+	Operation  string    `form:"operation"`
+	EntityType string    `form:"entity_type"`
+	EntityID   string    `form:"entity_id"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	PageID     int32     `form:"page_id" binding:"required,min=1"`
+	PageSize   int32     `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// listAuditLog lists the audit log, newest first. Audit readers see every change,
+// other users only the ones they made.
+func (server *Server) listAuditLog(ctx *gin.Context) {
+	var req listAuditLogRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		err := errors.New("from must be before to")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !server.isAuditReader(authPayload.Username) {
+		if req.Actor != "" && req.Actor != authPayload.Username {
+			err := errors.New("only audit readers can see the changes of other users")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		req.Actor = authPayload.Username
+	}
+
+	rows, err := server.store.ListAuditLog(ctx, db.ListAuditLogParams{
+		Actor:       req.Actor,
+		Operation:   req.Operation,
+		EntityType:  req.EntityType,
+		EntityID:    req.EntityID,
+		FromTime:    sql.NullTime{Time: req.From, Valid: !req.From.IsZero()},
+		ToTime:      sql.NullTime{Time: req.To, Valid: !req.To.IsZero()},
+		LimitCount:  req.PageSize,
+		OffsetCount: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rows)
+}
+
+func (server *Server) isAuditReader(username string) bool {
+	for _, reader := range server.config.AuditReaders {
+		if reader == username {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAuditLogAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	auditor, _ := randomUser(t)
+
+	rows := []db.AuditLog{
+		{
+			ID:         2,
+			Actor:      user.Username,
+			Operation:  db.AuditTransferCreate,
+			EntityType: "transfer",
+			EntityID:   "7",
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OwnChanges",
+			query:    "?page_id=1&page_size=5&operation=transfer.create",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditLog(gomock.Any(), gomock.Eq(db.ListAuditLogParams{
+						Actor:       user.Username,
+						Operation:   db.AuditTransferCreate,
+						LimitCount:  5,
+						OffsetCount: 0,
+					})).
+					Times(1).
+					Return(rows, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"operation":"transfer.create"`)
+			},
+		},
+		{
+			name:     "OtherUser",
+			query:    "?page_id=1&page_size=5&actor=" + auditor.Username,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "AuditReader",
+			query:    "?page_id=2&page_size=10&from=2030-01-01T00:00:00Z&to=2030-02-01T00:00:00Z",
+			username: auditor.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ListAuditLogParams) ([]db.AuditLog, error) {
+						require.Empty(t, arg.Actor)
+						require.True(t, arg.FromTime.Valid)
+						require.True(t, arg.FromTime.Time.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+						require.True(t, arg.ToTime.Time.Equal(time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC)))
+						require.Equal(t, int32(10), arg.OffsetCount)
+						return rows, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidPeriod",
+			query:    "?page_id=1&page_size=5&from=2030-02-01T00:00:00Z&to=2030-01-01T00:00:00Z",
+			username: auditor.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.AuditReaders = []string{auditor.Username}
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, "/audit"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
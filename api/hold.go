@@ -0,0 +1,179 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultHoldTTL applies when the config doesn't set HOLD_TTL
+const defaultHoldTTL = 7 * 24 * time.Hour
+
+// authorizeTransfer reserves the amount on the source account, the money only moves on capture
+func (server *Server) authorizeTransfer(ctx *gin.Context, arg db.TransferTxParams) {
+	if ctx.GetHeader(idempotencyKeyHeader) != "" {
+		err := fmt.Errorf("%s is not supported with mode %s", idempotencyKeyHeader, transferModeAuthorize)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ttl := server.config.HoldTTL
+	if ttl <= 0 {
+		ttl = defaultHoldTTL
+	}
+
+	res, err := server.store.AuthorizeTx(ctx, db.AuthorizeTxParams{
+		TransferTxParams: arg,
+		ExpiresAt:        time.Now().Add(ttl),
+	})
+	if err != nil {
+		transferErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, res)
+}
+
+type holdURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, ok := server.partyHold(ctx, uri.ID, holdPayer|holdRecipient)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+type captureHoldRequest struct {
+	// Amount to capture; omitted or zero captures the whole hold
+	Amount int64 `json:"amount" binding:"min=0"`
+}
+
+// captureHold settles a hold in full or in part, whatever isn't captured is released.
+// Only the recipient can capture, the payer can't move money out of its own hold.
+func (server *Server) captureHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req captureHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.partyHold(ctx, uri.ID, holdRecipient); !ok {
+		return
+	}
+
+	res, err := server.store.CaptureHoldTx(ctx, db.CaptureHoldTxParams{
+		HoldID: uri.ID,
+		Amount: req.Amount,
+	})
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, res)
+}
+
+// voidHold releases a hold without moving any money. Either party can void,
+// but only while the hold is pending, a captured hold stays captured.
+func (server *Server) voidHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.partyHold(ctx, uri.ID, holdPayer|holdRecipient); !ok {
+		return
+	}
+
+	hold, err := server.store.VoidHoldTx(ctx, uri.ID)
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+// holdErrorResponse writes the response for an error returned by a capture or a void
+func holdErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, db.ErrHoldNotPending), errors.Is(err, db.ErrHoldExpired):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, db.ErrCaptureExceedsHold):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	default:
+		transferErrorResponse(ctx, err)
+	}
+}
+
+// holdParty is a side of a hold, parties can be combined to allow either of them
+type holdParty int
+
+const (
+	holdPayer holdParty = 1 << iota
+	holdRecipient
+)
+
+// partyHold fetches the hold and checks that the authenticated user owns the account of one of parties.
+// On failure the error response is already written and false is returned.
+func (server *Server) partyHold(ctx *gin.Context, id int64, parties holdParty) (db.Hold, bool) {
+	hold, err := server.store.GetHold(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return hold, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, false
+	}
+
+	var accountIDs []int64
+	if parties&holdPayer != 0 {
+		accountIDs = append(accountIDs, hold.FromAccountID)
+	}
+	if parties&holdRecipient != 0 {
+		accountIDs = append(accountIDs, hold.ToAccountID)
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	for _, accountID := range accountIDs {
+		acc, err := server.store.GetAccount(ctx, accountID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return hold, false
+		}
+
+		if acc.Owner == authPayload.Username {
+			return hold, true
+		}
+	}
+
+	err = errors.New("hold doesn't allow this action by the authenticated user")
+	ctx.JSON(http.StatusForbidden, errorResponse(err))
+	return hold, false
+}
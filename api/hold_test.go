@@ -0,0 +1,312 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeTransferAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	acc1 := randomAccount(user1.Username)
+	acc2 := randomAccount(user2.Username)
+	acc2.ID = acc1.ID + 1
+	acc1.Currency = util.USD
+	acc2.Currency = util.USD
+
+	body := gin.H{
+		"from_account_id": acc1.ID,
+		"to_account_id":   acc2.ID,
+		"amount":          10,
+		"currency":        util.USD,
+		"mode":            transferModeAuthorize,
+	}
+
+	testCases := []struct {
+		name           string
+		idempotencyKey string
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponse  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuthorizeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.AuthorizeTxParams) (db.AuthorizeTxResult, error) {
+						require.Equal(t, db.TransferTxParams{
+							FromAccountID: acc1.ID,
+							ToAccountID:   acc2.ID,
+							Amount:        10,
+						}, arg.TransferTxParams)
+						require.WithinDuration(t, time.Now().Add(defaultHoldTTL), arg.ExpiresAt, time.Minute)
+
+						return db.AuthorizeTxResult{
+							Hold: db.Hold{ID: 1, Amount: 10, Status: db.HoldStatusPending, ExpiresAt: arg.ExpiresAt},
+						}, nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"status":"pending"`)
+			},
+		},
+		{
+			name:           "WithIdempotencyKey",
+			idempotencyKey: "key",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AuthorizeTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuthorizeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuthorizeTxResult{}, &db.InsufficientFundsError{AccountID: acc1.ID, Available: 5, Requested: 10})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"available_balance":5`)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(acc1.ID)).AnyTimes().Return(acc1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(acc2.ID)).AnyTimes().Return(acc2, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+			if tc.idempotencyKey != "" {
+				req.Header.Set(idempotencyKeyHeader, tc.idempotencyKey)
+			}
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSettleHoldAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	acc1 := randomAccount(user1.Username)
+	acc2 := randomAccount(user2.Username)
+	acc2.ID = acc1.ID + 1
+
+	hold := db.Hold{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        100,
+		ToAmount:      100,
+		FxRate:        util.FXRateScale,
+		Status:        db.HoldStatusPending,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "CaptureByRecipient",
+			action:   "capture",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Eq(db.CaptureHoldTxParams{HoldID: hold.ID})).
+					Times(1).
+					Return(db.CaptureHoldTxResult{Hold: hold}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "PartialCapture",
+			action:   "capture",
+			body:     gin.H{"amount": 40},
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Eq(db.CaptureHoldTxParams{HoldID: hold.ID, Amount: 40})).
+					Times(1).
+					Return(db.CaptureHoldTxResult{Hold: hold}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "CaptureExceedsHold",
+			action:   "capture",
+			body:     gin.H{"amount": 200},
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CaptureHoldTxResult{}, fmt.Errorf("%w: 200 of 100", db.ErrCaptureExceedsHold))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "CaptureExpired",
+			action:   "capture",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CaptureHoldTxResult{}, db.ErrHoldExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "CaptureByPayer",
+			action:   "capture",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "Void",
+			action:   "void",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				voided := hold
+				voided.Status = db.HoldStatusVoided
+
+				store.EXPECT().
+					VoidHoldTx(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(voided, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"status":"voided"`)
+			},
+		},
+		{
+			name:     "VoidByRecipient",
+			action:   "void",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VoidHoldTx(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(hold, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "VoidNotPending",
+			action:   "void",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VoidHoldTx(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(db.Hold{}, db.ErrHoldNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			action:   "capture",
+			username: "unauthorized",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(acc1.ID)).AnyTimes().Return(acc1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(acc2.ID)).AnyTimes().Return(acc2, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				err := json.NewEncoder(&body).Encode(tc.body)
+				require.NoError(t, err)
+			}
+
+			url := fmt.Sprintf("/holds/%d/%s", hold.ID, tc.action)
+			req, err := http.NewRequest(http.MethodPost, url, &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"os"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/util"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	fxRates := util.NewStaticFXRateProvider(map[string]int64{
+		util.USD + "/" + util.EUR: 92_000_000,
+		util.USD + "/" + util.CAD: 135_000_000,
+	})
+
+	return NewServer(config, store, tokenMaker, fxRates)
+}
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
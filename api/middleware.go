@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationPayloadKey = "authorization_payload"
+	requestIDHeaderKey      = "X-Request-Id"
+	maxRequestIDLength      = 64
+)
+
+// authMiddleware creates a gin middleware that only lets requests with a valid bearer access token through.
+// The verified payload is stored in the context under authorizationPayloadKey.
+func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			err := errors.New("authorization header is not provided")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) != 2 {
+			err := errors.New("invalid authorization header format")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		authorizationType := strings.ToLower(fields[0])
+		if authorizationType != authorizationTypeBearer {
+			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		payload, err := tokenMaker.VerifyToken(fields[1])
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		// refresh tokens outlive access tokens and are only good for renewing them
+		if payload.Type != token.TokenTypeAccess {
+			err := errors.New("not an access token")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.Set(authorizationPayloadKey, payload)
+		setAuditActor(ctx, payload.Username)
+		ctx.Next()
+	}
+}
+
+// operatorMiddleware creates a gin middleware that only lets the configured operators through,
+// it must run after authMiddleware
+func (server *Server) operatorMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if !server.isOperator(authPayload.Username) {
+			err := errors.New("only operators can access this resource")
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func (server *Server) isOperator(username string) bool {
+	for _, operator := range server.config.Operators {
+		if operator == username {
+			return true
+		}
+	}
+	return false
+}
+
+// auditMiddleware creates a gin middleware that tags the request context with a request id and the
+// client IP, for the audit log. The id is taken from the X-Request-Id header or generated, and echoed back.
+func auditMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeaderKey)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		ctx.Header(requestIDHeaderKey, requestID)
+
+		ctx.Request = ctx.Request.WithContext(db.WithAuditActor(ctx.Request.Context(), db.AuditActor{
+			Username:  db.AuditActorAnonymous,
+			RequestID: requestID,
+			ClientIP:  ctx.ClientIP(),
+		}))
+		ctx.Next()
+	}
+}
+
+// setAuditActor records the changes made by the rest of the request for username
+func setAuditActor(ctx *gin.Context, username string) {
+	actor := db.AuditActorFrom(ctx.Request.Context())
+	actor.Username = username
+	ctx.Request = ctx.Request.WithContext(db.WithAuditActor(ctx.Request.Context(), actor))
+}
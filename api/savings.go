@@ -0,0 +1,113 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type createSavingsAccountRequest struct {
+	ProductID int64 `json:"product_id" binding:"required,min=1"`
+}
+
+// createSavingsAccount puts an account on a savings product, it accrues interest from today on
+func (server *Server) createSavingsAccount(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req createSavingsAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	acc, ok := server.ownedAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	if acc.Status == db.AccountStatusClosed {
+		err := fmt.Errorf("%w: account [%d]", db.ErrAccountClosed, acc.ID)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	product, err := server.store.GetAccountProduct(ctx, req.ProductID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if product.Currency != acc.Currency {
+		err := fmt.Errorf("product [%d] is in %s, account [%d] in %s", product.ID, product.Currency, acc.ID, acc.Currency)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	savings, err := server.store.CreateSavingsAccount(ctx, db.CreateSavingsAccountParams{
+		AccountID:   acc.ID,
+		ProductID:   product.ID,
+		AccruesFrom: time.Now().UTC(),
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, savings)
+}
+
+type listInterestAccrualsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=31"`
+}
+
+// listInterestAccruals lists the daily interest accrued on an account, newest day first
+func (server *Server) listInterestAccruals(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listInterestAccrualsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, uri.ID); !ok {
+		return
+	}
+
+	accruals, err := server.store.ListInterestAccruals(ctx, db.ListInterestAccrualsParams{
+		AccountID: uri.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accruals)
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSavingsAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	acc := randomAccount(user.Username)
+	otherAcc := randomAccount("someone-else")
+
+	closedAcc := randomAccount(user.Username)
+	closedAcc.Status = db.AccountStatusClosed
+
+	product := db.AccountProduct{
+		ID:          7,
+		Name:        "savings",
+		Currency:    acc.Currency,
+		RateBps:     425,
+		DayCount:    util.DayCountACT365,
+		Compounding: util.CompoundingMonthly,
+	}
+
+	otherProduct := product
+	otherProduct.ID = 8
+	for otherProduct.Currency == acc.Currency {
+		otherProduct.Currency = util.RandomCurrency()
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: acc.ID,
+			body:      gin.H{"product_id": product.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(acc.ID)).
+					Times(1).
+					Return(acc, nil)
+				store.EXPECT().
+					GetAccountProduct(gomock.Any(), gomock.Eq(product.ID)).
+					Times(1).
+					Return(product, nil)
+				store.EXPECT().
+					CreateSavingsAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSavingsAccountParams) (db.SavingsAccount, error) {
+						require.Equal(t, acc.ID, arg.AccountID)
+						require.Equal(t, product.ID, arg.ProductID)
+						require.WithinDuration(t, time.Now(), arg.AccruesFrom, time.Minute)
+
+						return db.SavingsAccount{
+							AccountID:   arg.AccountID,
+							ProductID:   arg.ProductID,
+							AccruesFrom: arg.AccruesFrom,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var savings db.SavingsAccount
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &savings))
+				require.Equal(t, acc.ID, savings.AccountID)
+				require.Equal(t, product.ID, savings.ProductID)
+			},
+		},
+		{
+			name:      "MissingProduct",
+			accountID: acc.ID,
+			body:      gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSavingsAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NotOwner",
+			accountID: otherAcc.ID,
+			body:      gin.H{"product_id": product.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(otherAcc.ID)).
+					Times(1).
+					Return(otherAcc, nil)
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSavingsAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "ClosedAccount",
+			accountID: closedAcc.ID,
+			body:      gin.H{"product_id": product.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(closedAcc.ID)).
+					Times(1).
+					Return(closedAcc, nil)
+				store.EXPECT().GetAccountProduct(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSavingsAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:      "ProductNotFound",
+			accountID: acc.ID,
+			body:      gin.H{"product_id": product.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(acc.ID)).
+					Times(1).
+					Return(acc, nil)
+				store.EXPECT().
+					GetAccountProduct(gomock.Any(), gomock.Eq(product.ID)).
+					Times(1).
+					Return(db.AccountProduct{}, sql.ErrNoRows)
+				store.EXPECT().CreateSavingsAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "CurrencyMismatch",
+			accountID: acc.ID,
+			body:      gin.H{"product_id": otherProduct.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(acc.ID)).
+					Times(1).
+					Return(acc, nil)
+				store.EXPECT().
+					GetAccountProduct(gomock.Any(), gomock.Eq(otherProduct.ID)).
+					Times(1).
+					Return(otherProduct, nil)
+				store.EXPECT().CreateSavingsAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "AlreadySavings",
+			accountID: acc.ID,
+			body:      gin.H{"product_id": product.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(acc.ID)).
+					Times(1).
+					Return(acc, nil)
+				store.EXPECT().
+					GetAccountProduct(gomock.Any(), gomock.Eq(product.ID)).
+					Times(1).
+					Return(product, nil)
+				store.EXPECT().
+					CreateSavingsAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.SavingsAccount{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/savings", tc.accountID)
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListInterestAccrualsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	acc := randomAccount(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accruals := []db.InterestAccrual{
+		{ID: 2, AccountID: acc.ID, AccrualDate: time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC), Amount: 12},
+		{ID: 1, AccountID: acc.ID, AccrualDate: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), Amount: 11},
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(acc.ID)).
+		Times(1).
+		Return(acc, nil)
+	store.EXPECT().
+		ListInterestAccruals(gomock.Any(), gomock.Eq(db.ListInterestAccrualsParams{
+			AccountID: acc.ID,
+			Limit:     10,
+			Offset:    10,
+		})).
+		Times(1).
+		Return(accruals, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/accounts/%d/accruals?page_id=2&page_size=10", acc.ID)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []db.InterestAccrual
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Len(t, got, 2)
+	require.Equal(t, int64(12), got[0].Amount)
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64     `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64     `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64     `json:"amount" binding:"required,gt=0"`
+	Currency      string    `json:"currency" binding:"required,currency"`
+	Recurrence    string    `json:"recurrence" binding:"required,oneof=once daily weekly monthly"`
+	ExecuteAt     time.Time `json:"execute_at" binding:"required"`
+	// DayOfMonth is required for monthly schedules, shorter months use their last day
+	DayOfMonth int32 `json:"day_of_month" binding:"omitempty,min=1,max=31"`
+}
+
+// createScheduledTransfer schedules a transfer at execute_at, repeated according to recurrence.
+// Monthly schedules start on the first day_of_month at or after execute_at.
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.ExecuteAt.After(time.Now()) {
+		err := errors.New("execute_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if (req.Recurrence == db.RecurrenceMonthly) != (req.DayOfMonth != 0) {
+		err := errors.New("day_of_month is required for monthly schedules and only allowed for them")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.validAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	arg := db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Recurrence:    req.Recurrence,
+		ScheduledFor:  req.ExecuteAt,
+	}
+
+	if req.Recurrence == db.RecurrenceMonthly {
+		arg.DayOfMonth = sql.NullInt32{Int32: req.DayOfMonth, Valid: true}
+		arg.ScheduledFor = db.FirstMonthlyOccurrence(req.ExecuteAt, req.DayOfMonth)
+	}
+
+	schedule, err := server.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, schedule)
+}
+
+type scheduledTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	schedule, ok := server.ownedScheduledTransfer(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+type listScheduledTransferRunsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=15"`
+}
+
+func (server *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listScheduledTransferRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedScheduledTransfer(ctx, uri.ID); !ok {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: uri.ID,
+		Limit:               req.PageSize,
+		Offset:              (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+func (server *Server) pauseScheduledTransfer(ctx *gin.Context) {
+	server.changeScheduledTransferStatus(ctx, db.ScheduleStatusPaused)
+}
+
+func (server *Server) resumeScheduledTransfer(ctx *gin.Context) {
+	server.changeScheduledTransferStatus(ctx, db.ScheduleStatusActive)
+}
+
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	server.changeScheduledTransferStatus(ctx, db.ScheduleStatusCancelled)
+}
+
+func (server *Server) changeScheduledTransferStatus(ctx *gin.Context, status string) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedScheduledTransfer(ctx, uri.ID); !ok {
+		return
+	}
+
+	schedule, err := server.store.ChangeScheduledTransferStatusTx(ctx, db.ChangeScheduledTransferStatusTxParams{
+		ID:     uri.ID,
+		Status: status,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidStatusTransition) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+// ownedScheduledTransfer fetches the schedule and checks that it belongs to the authenticated user.
+// On failure the error response is already written and false is returned.
+func (server *Server) ownedScheduledTransfer(ctx *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	schedule, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return schedule, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return schedule, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if schedule.Owner != authPayload.Username {
+		err := errors.New("scheduled transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return schedule, false
+	}
+
+	return schedule, true
+}
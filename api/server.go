@@ -1,7 +1,10 @@
 package api
 
 import (
+	"expvar"
 	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/util"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -9,25 +12,83 @@ import (
 )
 
 type Server struct {
-	store  db.Store
-	router *gin.Engine
+	config     util.Config
+	store      db.Store
+	tokenMaker token.Maker
+	fxRates    util.FXRateProvider
+	router     *gin.Engine
 }
 
 // NewServer creates a new HTTP server and sets up routing
-func NewServer(store db.Store) *Server {
-	server := &Server{store: store}
+func NewServer(config util.Config, store db.Store, tokenMaker token.Maker, fxRates util.FXRateProvider) *Server {
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		fxRates:    fxRates,
+	}
 	router := gin.Default()
+	// handlers pass the gin context to the store, it must carry the values of the request context
+	router.ContextWithFallback = true
+	router.Use(auditMiddleware())
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("username", validUsername)
 	}
 
-	router.POST("/accounts", server.createAccount)
-	router.GET("/accounts/:id", server.getAccount)
-	router.GET("/accounts/", server.listAccounts)
-	router.PATCH("/accounts/:id", server.updateAccountBalance)
+	router.POST("/users", server.createUser)
+	router.POST("/users/login", server.loginUser)
+	router.POST("/tokens/renew_access", server.renewAccessToken)
+
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+
+	// exported metrics, like the ledger discrepancy count
+	authRoutes.GET("/debug/vars", server.operatorMiddleware(), gin.WrapH(expvar.Handler()))
+
+	authRoutes.POST("/accounts", server.createAccount)
+	authRoutes.GET("/accounts/:id", server.getAccount)
+	authRoutes.GET("/accounts/", server.listAccounts)
+	authRoutes.POST("/accounts/:id/freeze", server.freezeAccount)
+	authRoutes.POST("/accounts/:id/unfreeze", server.operatorMiddleware(), server.unfreezeAccount)
+	authRoutes.POST("/accounts/:id/close", server.closeAccount)
+	authRoutes.GET("/accounts/:id/balance", server.getAccountBalance)
+	authRoutes.GET("/accounts/:id/statement", server.getAccountStatement)
+	authRoutes.POST("/accounts/:id/adjustments", server.operatorMiddleware(), server.createAdjustment)
+	authRoutes.GET("/accounts/:id/adjustments", server.listAdjustments)
+	authRoutes.POST("/accounts/:id/savings", server.createSavingsAccount)
+	authRoutes.GET("/accounts/:id/accruals", server.listInterestAccruals)
+
+	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.POST("/transfers/quote", server.quoteTransfer)
+	authRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+
+	authRoutes.POST("/transfer_batches", server.createTransferBatch)
+	authRoutes.GET("/transfer_batches/:id", server.getTransferBatch)
+
+	authRoutes.POST("/transfer_imports", server.createTransferImport)
+
+	authRoutes.GET("/holds/:id", server.getHold)
+	authRoutes.POST("/holds/:id/capture", server.captureHold)
+	authRoutes.POST("/holds/:id/void", server.voidHold)
+
+	authRoutes.POST("/scheduled_transfers", server.createScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers/:id", server.getScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers/:id/runs", server.listScheduledTransferRuns)
+	authRoutes.POST("/scheduled_transfers/:id/pause", server.pauseScheduledTransfer)
+	authRoutes.POST("/scheduled_transfers/:id/resume", server.resumeScheduledTransfer)
+	authRoutes.POST("/scheduled_transfers/:id/cancel", server.cancelScheduledTransfer)
+
+	authRoutes.POST("/webhooks", server.createWebhook)
+	authRoutes.GET("/webhooks", server.listWebhooks)
+	authRoutes.DELETE("/webhooks/:id", server.deleteWebhook)
+	authRoutes.GET("/webhooks/:id/deliveries", server.listWebhookDeliveries)
+	authRoutes.POST("/webhooks/:id/replay", server.replayWebhook)
+
+	authRoutes.GET("/audit", server.listAuditLog)
 
-	router.POST("/transfers", server.createTransfer)
+	authRoutes.DELETE("/sessions", server.revokeAllSessions)
+	authRoutes.DELETE("/sessions/:id", server.revokeSession)
 
 	server.router = router
 	return server
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/statement"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxStatementPeriod bounds how many entries a single statement can hold
+const maxStatementPeriod = 366 * 24 * time.Hour
+
+type accountStatementRequest struct {
+	// From and To are RFC 3339 times, To is exclusive
+	From time.Time `form:"from" binding:"required"`
+	To   time.Time `form:"to" binding:"required"`
+	// Format overrides the Accept header
+	Format string `form:"format" binding:"omitempty,oneof=json csv ofx camt053"`
+}
+
+// getAccountStatement lists the entries of an account between two times with the running balance.
+// It answers in JSON or, depending on the format parameter or the Accept header, streams an export
+// in one of the formats of the statement package.
+func (serv *Server) getAccountStatement(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req accountStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// entries are dated in UTC
+	req.From = req.From.UTC()
+	req.To = req.To.UTC()
+
+	if !req.From.Before(req.To) {
+		err := errors.New("from must be before to")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		var ok bool
+		format, ok = statement.FormatOf(ctx.NegotiateFormat(statement.MediaTypes()...))
+		if !ok {
+			err := fmt.Errorf("statements are served as %s", strings.Join(statement.MediaTypes(), ", "))
+			ctx.JSON(http.StatusNotAcceptable, errorResponse(err))
+			return
+		}
+	}
+
+	// exports are streamed, only JSON statements are held in memory
+	if format == statement.FormatJSON && req.To.Sub(req.From) > maxStatementPeriod {
+		err := fmt.Errorf("a statement covers at most %d days", int(maxStatementPeriod.Hours()/24))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	acc, ok := serv.ownedAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	arg := db.AccountStatementTxParams{
+		AccountID: uri.ID,
+		From:      req.From,
+		To:        req.To,
+	}
+
+	if format != statement.FormatJSON {
+		serv.exportAccountStatement(ctx, acc, format, arg)
+		return
+	}
+
+	res, err := serv.store.AccountStatementTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// exportAccountStatement streams a statement to the client as a file of format
+func (serv *Server) exportAccountStatement(ctx *gin.Context, acc db.Account, format string, arg db.AccountStatementTxParams) {
+	w, err := statement.NewWriter(format, ctx.Writer, acc, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	export := &statementExport{Writer: w, ctx: ctx, format: format, arg: arg}
+	err = serv.store.StreamAccountStatementTx(ctx, arg, export)
+	if err == nil {
+		err = w.Close()
+	}
+
+	if err != nil {
+		if !export.started {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		// the status is already sent, the client is left with a truncated file
+		ctx.Error(err)
+		ctx.Abort()
+	}
+}
+
+// statementExport sends the response headers once the statement is read, so that errors
+// before its first byte still get an error response
+type statementExport struct {
+	statement.Writer
+	ctx     *gin.Context
+	format  string
+	arg     db.AccountStatementTxParams
+	started bool
+}
+
+func (export *statementExport) WriteHeader(res db.AccountStatement) error {
+	export.started = true
+
+	fileName := statement.FileName(export.format, export.arg)
+	export.ctx.Header("Content-Type", statement.MediaType(export.format))
+	export.ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	export.ctx.Status(http.StatusOK)
+
+	return export.Writer.WriteHeader(res)
+}
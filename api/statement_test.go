@@ -0,0 +1,307 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/statement"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountStatementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+	acc := randomAccount(user.Username)
+
+	from := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	res := db.AccountStatement{
+		AccountID:      acc.ID,
+		From:           from,
+		To:             to,
+		OpeningBalance: 100,
+		ClosingBalance: 70,
+		Entries: []db.ListStatementEntriesRow{
+			{
+				ID:                    1,
+				Amount:                -30,
+				TransferID:            sql.NullInt64{Int64: 5, Valid: true},
+				CreatedAt:             from.Add(time.Hour),
+				CounterpartyAccountID: sql.NullInt64{Int64: acc.ID + 1, Valid: true},
+				RunningBalance:        70,
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		from          string
+		to            string
+		format        string
+		accept        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			from:     from.Format(time.RFC3339),
+			to:       to.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(acc.ID)).Times(1).Return(acc, nil)
+
+				arg := db.AccountStatementTxParams{
+					AccountID: acc.ID,
+					From:      from,
+					To:        to,
+				}
+				store.EXPECT().AccountStatementTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(res, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.AccountStatement
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, res.OpeningBalance, got.OpeningBalance)
+				require.Equal(t, res.ClosingBalance, got.ClosingBalance)
+				require.Equal(t, res.Entries[0].CounterpartyAccountID, got.Entries[0].CounterpartyAccountID)
+				require.Equal(t, res.Entries[0].RunningBalance, got.Entries[0].RunningBalance)
+			},
+		},
+		{
+			name:     "NonUTCOffset",
+			username: user.Username,
+			from:     from.In(time.FixedZone("", 2*60*60)).Format(time.RFC3339),
+			to:       to.In(time.FixedZone("", -5*60*60)).Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(acc.ID)).Times(1).Return(acc, nil)
+
+				// the bounds reach the store in UTC, the zone entries are dated in
+				arg := db.AccountStatementTxParams{
+					AccountID: acc.ID,
+					From:      from,
+					To:        to,
+				}
+				store.EXPECT().AccountStatementTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(res, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Unauthorized",
+			username: otherUser.Username,
+			from:     from.Format(time.RFC3339),
+			to:       to.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(acc.ID)).Times(1).Return(acc, nil)
+				store.EXPECT().AccountStatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "FromAfterTo",
+			username: user.Username,
+			from:     to.Format(time.RFC3339),
+			to:       from.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AccountStatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "PeriodTooLong",
+			username: user.Username,
+			from:     from.Format(time.RFC3339),
+			to:       from.AddDate(2, 0, 0).Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AccountStatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "ExportCSV",
+			username: user.Username,
+			from:     from.Format(time.RFC3339),
+			to:       to.Format(time.RFC3339),
+			format:   statement.FormatCSV,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(acc.ID)).Times(1).Return(acc, nil)
+				store.EXPECT().AccountStatementTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					StreamAccountStatementTx(gomock.Any(), gomock.Eq(db.AccountStatementTxParams{AccountID: acc.ID, From: from, To: to}), gomock.Any()).
+					Times(1).
+					DoAndReturn(streamStatement(res, nil))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+
+				fileName := fmt.Sprintf("statement-%d-20300101-20300201.csv", acc.ID)
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), fileName)
+
+				records, err := csv.NewReader(recorder.Body).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, records, 2)
+				require.Equal(t, "-0.30", records[1][2])
+			},
+		},
+		{
+			name:     "AcceptOFX",
+			username: user.Username,
+			from:     from.Format(time.RFC3339),
+			to:       to.Format(time.RFC3339),
+			accept:   "application/x-ofx",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(acc.ID)).Times(1).Return(acc, nil)
+				store.EXPECT().StreamAccountStatementTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(streamStatement(res, nil))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/x-ofx", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "<TRNAMT>-0.30</TRNAMT>")
+			},
+		},
+		{
+			name:     "ExportLongPeriod",
+			username: user.Username,
+			from:     from.Format(time.RFC3339),
+			to:       from.AddDate(2, 0, 0).Format(time.RFC3339),
+			format:   statement.FormatCamt053,
+			accept:   "application/json",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(acc.ID)).Times(1).Return(acc, nil)
+				store.EXPECT().StreamAccountStatementTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).DoAndReturn(streamStatement(res, nil))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, statement.MediaType(statement.FormatCamt053), recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "<Ntry>")
+			},
+		},
+		{
+			name:     "NotAcceptable",
+			username: user.Username,
+			from:     from.Format(time.RFC3339),
+			to:       to.Format(time.RFC3339),
+			accept:   "image/png",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().StreamAccountStatementTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotAcceptable, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidFormat",
+			username: user.Username,
+			from:     from.Format(time.RFC3339),
+			to:       to.Format(time.RFC3339),
+			format:   "pdf",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "ExportInternalError",
+			username: user.Username,
+			from:     from.Format(time.RFC3339),
+			to:       to.Format(time.RFC3339),
+			format:   statement.FormatCSV,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(acc.ID)).Times(1).Return(acc, nil)
+				store.EXPECT().StreamAccountStatementTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "application/json")
+			},
+		},
+		{
+			name:     "InvalidTime",
+			username: user.Username,
+			from:     "yesterday",
+			to:       to.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AccountStatementTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			query := url.Values{"from": {tc.from}, "to": {tc.to}}
+			if tc.format != "" {
+				query.Set("format", tc.format)
+			}
+			url := fmt.Sprintf("/accounts/%d/statement?%s", acc.ID, query.Encode())
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// streamStatement stubs StreamAccountStatementTx by writing res, then returning err
+func streamStatement(res db.AccountStatement, err error) func(context.Context, db.AccountStatementTxParams, db.StatementWriter) error {
+	return func(_ context.Context, _ db.AccountStatementTxParams, w db.StatementWriter) error {
+		entries := res.Entries
+		res.Entries = nil
+
+		if err := w.WriteHeader(res); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := w.WriteEntry(entry); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildSession  func(refreshToken string, payload *token.Payload) db.Session
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{
+					ID:           payload.ID,
+					Username:     payload.Username,
+					RefreshToken: refreshToken,
+					ExpiresAt:    payload.ExpiredAt,
+				}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got renewAccessTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.NotEmpty(t, got.AccessToken)
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{
+					ID:           payload.ID,
+					Username:     payload.Username,
+					RefreshToken: refreshToken,
+					IsBlocked:    true,
+					ExpiresAt:    payload.ExpiredAt,
+				}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MismatchedToken",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{
+					ID:           payload.ID,
+					Username:     payload.Username,
+					RefreshToken: "another-token",
+					ExpiresAt:    payload.ExpiredAt,
+				}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			buildSession: func(refreshToken string, payload *token.Payload) db.Session {
+				return db.Session{}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+
+			refreshToken, payload, err := server.tokenMaker.CreateToken(user.Username, token.TokenTypeRefresh, time.Hour)
+			require.NoError(t, err)
+
+			session := tc.buildSession(refreshToken, payload)
+			if session.ID == payload.ID {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(session, nil)
+			} else {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+			}
+
+			data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, "/tokens/renew_access", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRenewAccessTokenWithAccessToken(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	accessToken, _, err := server.tokenMaker.CreateToken(user.Username, token.TokenTypeAccess, time.Hour)
+	require.NoError(t, err)
+
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	data, err := json.Marshal(gin.H{"refresh_token": accessToken})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/tokens/renew_access", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestRevokeSessionAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	_, payload, err := server.tokenMaker.CreateToken(user.Username, token.TokenTypeRefresh, time.Hour)
+	require.NoError(t, err)
+
+	session := db.Session{ID: payload.ID, Username: user.Username}
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Eq(session.ID)).
+		Times(2).
+		Return(session, nil)
+	store.EXPECT().
+		BlockSession(gomock.Any(), gomock.Eq(session.ID)).
+		Times(1).
+		Return(session, nil)
+
+	// someone else's session is off limits
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodDelete, "/sessions/"+session.ID.String(), nil)
+	require.NoError(t, err)
+	addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, other.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodDelete, "/sessions/"+session.ID.String(), nil)
+	require.NoError(t, err)
+	addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+}
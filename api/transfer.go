@@ -1,10 +1,17 @@
 package api
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"simplebank/currency"
 	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/util"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,8 +21,17 @@ type createTransferRequest struct {
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
 	Amount        int64  `json:"amount" binding:"required,gt=0"`
 	Currency      string `json:"currency" binding:"required,currency"`
+	// ToCurrency optionally pins the recipient's currency, the transfer is rejected if it differs
+	ToCurrency string `json:"to_currency" binding:"omitempty,currency"`
+	// Mode is settle (default) to move the money now, or authorize to only hold it until capture
+	Mode string `json:"mode" binding:"omitempty,oneof=settle authorize"`
 }
 
+const (
+	transferModeSettle    = "settle"
+	transferModeAuthorize = "authorize"
+)
+
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var req createTransferRequest
 
@@ -24,44 +40,314 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	if !server.validAccount(ctx, req.FromAccountID, req.Currency) ||
-		!server.validAccount(ctx, req.ToAccountID, req.Currency) {
+	arg, ok := server.prepareTransfer(ctx, req)
+	if !ok {
+		return
+	}
+
+	if req.Mode == transferModeAuthorize {
+		server.authorizeTransfer(ctx, arg)
+		return
+	}
+
+	if key := ctx.GetHeader(idempotencyKeyHeader); key != "" {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		server.createIdempotentTransfer(ctx, req, arg, authPayload.Username, key)
+		return
+	}
+
+	res, err := server.store.TransferTx(ctx, arg)
+
+	if err != nil {
+		transferErrorResponse(ctx, err)
 		return
 	}
 
+	ctx.JSON(http.StatusCreated, res)
+}
+
+// quoteTransfer previews the fee and the amounts of a transfer request without moving any money
+func (server *Server) quoteTransfer(ctx *gin.Context) {
+	var req createTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg, ok := server.prepareTransfer(ctx, req)
+	if !ok {
+		return
+	}
+
+	quote, err := server.store.QuoteTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
+
+// prepareTransfer checks the accounts of a transfer request and builds the transfer params,
+// converting the amount when the currencies differ.
+// On failure the error response is already written and false is returned.
+func (server *Server) prepareTransfer(ctx *gin.Context, req createTransferRequest) (db.TransferTxParams, bool) {
 	arg := db.TransferTxParams{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
 	}
 
-	res, err := server.store.TransferTx(ctx, arg)
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return arg, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return arg, false
+	}
+
+	toAccount, valid := server.existingAccount(ctx, req.ToAccountID)
+	if !valid {
+		return arg, false
+	}
+
+	if req.ToCurrency != "" && toAccount.Currency != req.ToCurrency {
+		err := fmt.Errorf("account [%d] currency mismatch %s vs %s", toAccount.ID, toAccount.Currency, req.ToCurrency)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return arg, false
+	}
+
+	if fromAccount.Currency != toAccount.Currency {
+		if !server.convertTransfer(ctx, &arg, fromAccount.Currency, toAccount.Currency) {
+			return arg, false
+		}
+	}
+
+	return arg, true
+}
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// createIdempotentTransfer runs the transfer at most once per key and replays the stored response on retries
+func (server *Server) createIdempotentTransfer(ctx *gin.Context, req createTransferRequest, arg db.TransferTxParams, username string, key string) {
+	if len(key) > maxIdempotencyKeyLength {
+		err := fmt.Errorf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	res, err := server.store.IdempotentTransferTx(ctx, db.IdempotentTransferTxParams{
+		TransferTxParams: arg,
+		Username:         username,
+		IdempotencyKey:   key,
+		RequestHash:      transferRequestHash(req),
+	})
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if errors.Is(err, db.ErrIdempotencyKeyReused) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		transferErrorResponse(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, res)
+	if res.Replayed {
+		ctx.Header(idempotentReplayedHeader, "true")
+	}
+
+	ctx.JSON(http.StatusCreated, res.TransferTxResult)
+}
+
+// transferErrorResponse writes the response for an error returned by a transfer transaction
+func transferErrorResponse(ctx *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	body := errorResponse(err)
+
+	var fundsErr *db.InsufficientFundsError
+	var limitErr *db.TransferLimitError
+	switch {
+	case errors.As(err, &fundsErr):
+		status = http.StatusUnprocessableEntity
+		body["available_balance"] = fundsErr.Available
+	case errors.As(err, &limitErr):
+		status = http.StatusUnprocessableEntity
+		body["limit"] = limitErr.Limit
+		body["resets_at"] = limitErr.ResetsAt
+	case errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed):
+		status = http.StatusUnprocessableEntity
+	}
+
+	// batch failures point at the leg that failed
+	var legErr *db.BatchLegError
+	if errors.As(err, &legErr) {
+		body["leg"] = legErr.Index
+	}
+
+	ctx.JSON(status, body)
+}
+
+// transferRequestHash fingerprints the fields that define a transfer request
+func transferRequestHash(req createTransferRequest) string {
+	data := fmt.Sprintf("%d:%d:%d:%s:%s", req.FromAccountID, req.ToAccountID, req.Amount, req.Currency, req.ToCurrency)
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
 }
 
-func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) bool {
+// convertTransfer fills in the destination leg of a cross-currency transfer
+func (server *Server) convertTransfer(ctx *gin.Context, arg *db.TransferTxParams, from string, to string) bool {
+	rate, err := server.fxRates.Rate(ctx, from, to)
+	if err != nil {
+		if errors.Is(err, util.ErrFXRateNotFound) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	fromCurrency, ok := currency.Default().Lookup(from)
+	if !ok {
+		err := fmt.Errorf("unsupported currency %s", from)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return false
+	}
+
+	toCurrency, ok := currency.Default().Lookup(to)
+	if !ok {
+		err := fmt.Errorf("unsupported currency %s", to)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return false
+	}
+
+	toAmount, err := util.ConvertAmount(arg.Amount, rate, fromCurrency, toCurrency)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return false
+	}
+
+	if toAmount <= 0 {
+		err := fmt.Errorf("amount %d %s is too small to convert to %s", arg.Amount, from, to)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return false
+	}
+
+	arg.ToAmount = toAmount
+	arg.FxRate = rate
+	return true
+}
+
+// existingAccount fetches the account, writing a 404 or 500 response if that fails
+func (server *Server) existingAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
 	acc, err := server.store.GetAccount(ctx, accountID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return false
+			return acc, false
 		}
 
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return false
+		return acc, false
+	}
+
+	// house accounts only move through the ledger, never through transfers of users
+	if acc.Kind != db.AccountKindCustomer {
+		err := fmt.Errorf("account [%d] is a house account", acc.ID)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return acc, false
+	}
+
+	return acc, true
+}
+
+func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+	acc, valid := server.existingAccount(ctx, accountID)
+	if !valid {
+		return acc, false
 	}
 
 	if acc.Currency != currency {
 		err := fmt.Errorf("account [%d] currency mismatch %s vs %s", acc.ID, acc.Currency, currency)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return false
+		return acc, false
 	}
-	return true
+	return acc, true
+}
+
+type reverseTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reverseTransferRequest struct {
+	// Amount to refund; omitted or zero refunds the whole remaining amount
+	Amount int64 `json:"amount" binding:"min=0"`
+}
+
+// reverseTransfer refunds a transfer in full or in part. Only the owner of the
+// receiving account can give the money back.
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var uri reverseTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	original, err := server.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, original.ToAccountID); !ok {
+		return
+	}
+
+	res, err := server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: original.ID,
+		Amount:     req.Amount,
+	})
+
+	if err != nil {
+		var refundErr *db.RefundExceedsRemainingError
+		if errors.As(err, &refundErr) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":            err.Error(),
+				"remaining_amount": refundErr.Remaining,
+			})
+			return
+		}
+
+		if errors.Is(err, db.ErrTransferIsReversal) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		transferErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, res)
 }
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+type batchLegRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+}
+
+// createTransferBatchRequest caps the legs so a batch transaction stays reasonably short
+type createTransferBatchRequest struct {
+	Legs []batchLegRequest `json:"legs" binding:"required,min=1,max=1000,dive"`
+}
+
+// createTransferBatch runs all the legs atomically, every source account must belong to the user.
+// Legs are same-currency, both accounts of a leg must be in the leg's currency.
+func (server *Server) createTransferBatch(ctx *gin.Context) {
+	var req createTransferBatchRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	accounts, ok := server.batchAccounts(ctx, req.Legs)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	legs := make([]db.TransferTxParams, len(req.Legs))
+
+	for i, leg := range req.Legs {
+		for _, id := range []int64{leg.FromAccountID, leg.ToAccountID} {
+			acc, ok := accounts[id]
+			if !ok {
+				err := fmt.Errorf("leg %d: account [%d] not found", i, id)
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+
+			if acc.Currency != leg.Currency {
+				err := fmt.Errorf("leg %d: account [%d] currency mismatch %s vs %s", i, id, acc.Currency, leg.Currency)
+				ctx.JSON(http.StatusBadRequest, errorResponse(err))
+				return
+			}
+
+			if acc.Kind != db.AccountKindCustomer {
+				err := fmt.Errorf("leg %d: account [%d] is a house account", i, id)
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+
+		if accounts[leg.FromAccountID].Owner != authPayload.Username {
+			err := fmt.Errorf("leg %d: from account doesn't belong to the authenticated user", i)
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		legs[i] = db.TransferTxParams{
+			FromAccountID: leg.FromAccountID,
+			ToAccountID:   leg.ToAccountID,
+			Amount:        leg.Amount,
+		}
+	}
+
+	res, err := server.store.TransferBatchTx(ctx, db.TransferBatchTxParams{
+		Owner: authPayload.Username,
+		Legs:  legs,
+	})
+	if err != nil {
+		transferErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, res)
+}
+
+// batchAccounts fetches every account of the legs in one query, keyed by id
+func (server *Server) batchAccounts(ctx *gin.Context, legs []batchLegRequest) (map[int64]db.Account, bool) {
+	ids := make([]int64, 0, len(legs)+1)
+	for _, leg := range legs {
+		ids = append(ids, leg.FromAccountID, leg.ToAccountID)
+	}
+
+	list, err := server.store.ListAccountsByID(ctx, ids)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+
+	accounts := make(map[int64]db.Account, len(list))
+	for _, acc := range list {
+		accounts[acc.ID] = acc
+	}
+	return accounts, true
+}
+
+type transferBatchURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type transferBatchResponse struct {
+	Batch     db.TransferBatch `json:"batch"`
+	Transfers []db.Transfer    `json:"transfers"`
+}
+
+func (server *Server) getTransferBatch(ctx *gin.Context) {
+	var uri transferBatchURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	batch, err := server.store.GetTransferBatch(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if batch.Owner != authPayload.Username {
+		err := errors.New("batch doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	transfers, err := server.store.ListBatchTransfers(ctx, sql.NullInt64{Int64: batch.ID, Valid: true})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transferBatchResponse{
+		Batch:     batch,
+		Transfers: transfers,
+	})
+}
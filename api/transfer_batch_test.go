@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTransferBatchAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	payer := randomAccount(user1.Username)
+	payer.Currency = util.USD
+
+	payee1 := randomAccount(user2.Username)
+	payee1.ID = payer.ID + 1
+	payee1.Currency = util.USD
+
+	payee2 := randomAccount(user2.Username)
+	payee2.ID = payer.ID + 2
+	payee2.Currency = util.USD
+
+	euroAccount := randomAccount(user2.Username)
+	euroAccount.ID = payer.ID + 3
+	euroAccount.Currency = util.EUR
+
+	accounts := []db.Account{payer, payee1, payee2, euroAccount}
+
+	leg := func(from db.Account, to db.Account) gin.H {
+		return gin.H{
+			"from_account_id": from.ID,
+			"to_account_id":   to.ID,
+			"amount":          10,
+			"currency":        util.USD,
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		legs          []gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			legs: []gin.H{leg(payer, payee1), leg(payer, payee2)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TransferBatchTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TransferBatchTxParams) (db.TransferBatchTxResult, error) {
+						require.Equal(t, user1.Username, arg.Owner)
+						require.Equal(t, []db.TransferTxParams{
+							{FromAccountID: payer.ID, ToAccountID: payee1.ID, Amount: 10},
+							{FromAccountID: payer.ID, ToAccountID: payee2.ID, Amount: 10},
+						}, arg.Legs)
+
+						return db.TransferBatchTxResult{
+							Batch: db.TransferBatch{ID: 1, Owner: arg.Owner},
+							Legs:  make([]db.TransferTxResult, len(arg.Legs)),
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got db.TransferBatchTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), got.Batch.ID)
+				require.Len(t, got.Legs, 2)
+			},
+		},
+		{
+			name: "NoLegs",
+			legs: []gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "FromAccountNotOwned",
+			legs: []gin.H{leg(payer, payee1), leg(payee1, payee2)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			legs: []gin.H{leg(payer, db.Account{ID: payer.ID + 100})},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			legs: []gin.H{leg(payer, payee1), leg(payer, euroAccount)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "LegInsufficientFunds",
+			legs: []gin.H{leg(payer, payee1), leg(payer, payee2)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TransferBatchTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferBatchTxResult{}, &db.BatchLegError{
+						Index: 1,
+						Err:   &db.InsufficientFundsError{AccountID: payer.ID, Available: 5, Requested: 10},
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var got map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, float64(1), got["leg"])
+				require.Equal(t, float64(5), got["available_balance"])
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Any()).AnyTimes().Return(accounts, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"legs": tc.legs})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/transfer_batches", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
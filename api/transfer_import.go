@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"simplebank/importer"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxImportFileSize bounds the uploads read into memory
+	maxImportFileSize = 10 << 20
+	// maxImportLines keeps an import within a request, like the legs of a batch
+	maxImportLines = 1000
+)
+
+type createTransferImportRequest struct {
+	File *multipart.FileHeader `form:"file" binding:"required"`
+	// Format is guessed from the file name when empty
+	Format string `form:"format" binding:"omitempty,oneof=csv pain001"`
+	DryRun bool   `form:"dry_run"`
+}
+
+// createTransferImport runs the payment instructions of an uploaded CSV or pain.001 file, every
+// source account must belong to the user. Nothing runs unless all the lines are valid.
+func (server *Server) createTransferImport(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportFileSize)
+
+	var req createTransferImportRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		var ok bool
+		format, ok = importer.DetectFormat(req.File.Filename)
+		if !ok {
+			err := fmt.Errorf("cannot tell the format of %s, set format to csv or pain001", req.File.Filename)
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	defer file.Close()
+
+	lines, err := importer.Parse(format, file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if len(lines) == 0 {
+		err := errors.New("file has no payment instructions")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if len(lines) > maxImportLines {
+		err := fmt.Errorf("file has %d payment instructions, at most %d are imported at once", len(lines), maxImportLines)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	report, err := importer.Run(ctx, server.store, lines, importer.RunParams{
+		Owner:  authPayload.Username,
+		DryRun: req.DryRun,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	switch {
+	case report.Invalid > 0:
+		ctx.JSON(http.StatusUnprocessableEntity, report)
+	case report.DryRun:
+		ctx.JSON(http.StatusOK, report)
+	default:
+		ctx.JSON(http.StatusCreated, report)
+	}
+}
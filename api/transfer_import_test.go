@@ -0,0 +1,199 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/importer"
+	"simplebank/util"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTransferImportAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	payer := randomAccount(user1.Username)
+	payer.Currency = util.USD
+
+	payee := randomAccount(user2.Username)
+	payee.ID = payer.ID + 1
+	payee.Currency = util.USD
+
+	accounts := []db.Account{payer, payee}
+
+	csvFile := fmt.Sprintf("from_account_id,to_account_id,amount,currency\n%d,%d,1.50,USD\n%d,%d,2,USD\n",
+		payer.ID, payee.ID, payer.ID, payee.ID)
+
+	painFile := fmt.Sprintf(`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"><CstmrCdtTrfInitn>
+<PmtInf><DbtrAcct><Id><Othr><Id>%d</Id></Othr></Id></DbtrAcct>
+<CdtTrfTxInf><PmtId><EndToEndId>E2E-1</EndToEndId></PmtId><Amt><InstdAmt Ccy="USD">1.50</InstdAmt></Amt>
+<CdtrAcct><Id><Othr><Id>%d</Id></Othr></Id></CdtrAcct></CdtTrfTxInf>
+</PmtInf></CstmrCdtTrfInitn></Document>`, payer.ID, payee.ID)
+
+	testCases := []struct {
+		name          string
+		fileName      string
+		file          string
+		fields        map[string]string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			fileName: "payments.csv",
+			file:     csvFile,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Any()).Times(1).Return(accounts, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 150})).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: db.Transfer{ID: 1}}, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: 200})).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: db.Transfer{ID: 2}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				report := unmarshalImportReport(t, recorder)
+				require.Equal(t, 2, report.Completed)
+				require.Equal(t, int64(2), report.Lines[1].TransferID)
+			},
+		},
+		{
+			name:     "DryRunPain001",
+			fileName: "payments.xml",
+			file:     painFile,
+			fields:   map[string]string{"dry_run": "true"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Any()).Times(1).Return(accounts, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				report := unmarshalImportReport(t, recorder)
+				require.True(t, report.DryRun)
+				require.Len(t, report.Lines, 1)
+				require.Equal(t, importer.StatusValid, report.Lines[0].Status)
+				require.Equal(t, "E2E-1", report.Lines[0].Reference)
+			},
+		},
+		{
+			name:     "InvalidLines",
+			fileName: "payments.csv",
+			file:     fmt.Sprintf("from_account_id,to_account_id,amount,currency\n%d,%d,1,USD\n", payee.ID, payer.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Any()).Times(1).Return(accounts, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				report := unmarshalImportReport(t, recorder)
+				require.Equal(t, 1, report.Invalid)
+				require.Equal(t, importer.StatusInvalid, report.Lines[0].Status)
+			},
+		},
+		{
+			name:     "UnknownFormat",
+			fileName: "payments.txt",
+			file:     csvFile,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "ExplicitFormat",
+			fileName: "payments.txt",
+			file:     csvFile,
+			fields:   map[string]string{"format": importer.FormatCSV, "dry_run": "true"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Any()).Times(1).Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "MalformedFile",
+			fileName: "payments.xml",
+			file:     "<Document>",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			fileName: "payments.csv",
+			file:     csvFile,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+
+			part, err := writer.CreateFormFile("file", tc.fileName)
+			require.NoError(t, err)
+			_, err = part.Write([]byte(tc.file))
+			require.NoError(t, err)
+
+			for name, value := range tc.fields {
+				require.NoError(t, writer.WriteField(name, value))
+			}
+			require.NoError(t, writer.Close())
+
+			req, err := http.NewRequest(http.MethodPost, "/transfer_imports", &body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func unmarshalImportReport(t *testing.T, recorder *httptest.ResponseRecorder) importer.Report {
+	var report importer.Report
+	err := json.Unmarshal(recorder.Body.Bytes(), &report)
+	require.NoError(t, err)
+	return report
+}
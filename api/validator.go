@@ -1,14 +1,34 @@
 package api
 
 import (
-	"simplebank/util"
+	"simplebank/currency"
+	db "simplebank/db/sqlc"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
 
 var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
-	if currency, ok := fieldLevel.Field().Interface().(string); ok {
-		return util.IsSupported(currency)
+	if code, ok := fieldLevel.Field().Interface().(string); ok {
+		return currency.IsSupported(code)
 	}
 	return false
 }
+
+// reservedUsernames name the actors of the audit log and the owner of the house accounts,
+// no user can sign up under them
+var reservedUsernames = []string{db.AuditActorSystem, db.AuditActorAnonymous, db.HouseOwner}
+
+var validUsername validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	username, ok := fieldLevel.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	for _, reserved := range reservedUsernames {
+		if strings.EqualFold(username, reserved) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+	"simplebank/util"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type createWebhookRequest struct {
+	URL string `json:"url" binding:"required,http_url"`
+	// EventTypes subscribes to some event types only, empty subscribes to all of them
+	EventTypes []string `json:"event_types" binding:"omitempty,dive,oneof=account.created transfer.created account.balance_changed"`
+}
+
+// webhookResponse is what the API exposes about a webhook, the secret is only shown once it's created
+type webhookResponse struct {
+	ID         int64     `json:"id"`
+	Owner      string    `json:"owner"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newWebhookResponse(webhook db.Webhook) webhookResponse {
+	return webhookResponse{
+		ID:         webhook.ID,
+		Owner:      webhook.Owner,
+		URL:        webhook.Url,
+		EventTypes: webhook.EventTypes,
+		CreatedAt:  webhook.CreatedAt,
+	}
+}
+
+type createWebhookResponse struct {
+	webhookResponse
+	// Secret signs the deliveries, see util.VerifyWebhook
+	Secret string `json:"secret"`
+}
+
+// createWebhook registers a URL that is sent the events of the authenticated user's accounts
+func (server *Server) createWebhook(ctx *gin.Context) {
+	var req createWebhookRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := util.CheckWebhookURL(req.URL); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	secret, err := util.NewWebhookSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if req.EventTypes == nil {
+		req.EventTypes = []string{}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	webhook, err := server.store.CreateWebhook(ctx, db.CreateWebhookParams{
+		Owner:      authPayload.Username,
+		Url:        req.URL,
+		Secret:     secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createWebhookResponse{
+		webhookResponse: newWebhookResponse(webhook),
+		Secret:          webhook.Secret,
+	})
+}
+
+func (server *Server) listWebhooks(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	webhooks, err := server.store.ListWebhooks(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := make([]webhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		res[i] = newWebhookResponse(webhook)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type webhookURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// deleteWebhook stops the deliveries of a webhook, its delivery log goes with it
+func (server *Server) deleteWebhook(ctx *gin.Context) {
+	var uri webhookURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedWebhook(ctx, uri.ID); !ok {
+		return
+	}
+
+	if err := server.store.DeleteWebhook(ctx, uri.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type listWebhookDeliveriesRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=15"`
+}
+
+// listWebhookDeliveries lists the deliveries of a webhook, newest first, optionally of one status only
+func (server *Server) listWebhookDeliveries(ctx *gin.Context) {
+	var uri webhookURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listWebhookDeliveriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedWebhook(ctx, uri.ID); !ok {
+		return
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		WebhookID:   uri.ID,
+		Status:      req.Status,
+		LimitCount:  req.PageSize,
+		OffsetCount: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+type replayWebhookRequest struct {
+	// DeliveryIDs replays these deliveries, dead or delivered. Empty replays every dead delivery.
+	DeliveryIDs []int64 `json:"delivery_ids" binding:"omitempty,max=100,dive,min=1"`
+}
+
+// replayWebhook sends deliveries of a webhook again from their first attempt
+func (server *Server) replayWebhook(ctx *gin.Context) {
+	var uri webhookURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req replayWebhookRequest
+	// the body is optional, an empty one replays the dead deliveries
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	if _, ok := server.ownedWebhook(ctx, uri.ID); !ok {
+		return
+	}
+
+	if req.DeliveryIDs == nil {
+		req.DeliveryIDs = []int64{}
+	}
+
+	deliveries, err := server.store.ReplayWebhookDeliveries(ctx, db.ReplayWebhookDeliveriesParams{
+		Now:       time.Now(),
+		WebhookID: uri.ID,
+		Ids:       req.DeliveryIDs,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+// ownedWebhook fetches the webhook and checks that it belongs to the authenticated user.
+// On failure the error response is already written and false is returned.
+func (server *Server) ownedWebhook(ctx *gin.Context, id int64) (db.Webhook, bool) {
+	webhook, err := server.store.GetWebhook(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return webhook, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return webhook, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if webhook.Owner != authPayload.Username {
+		err := errors.New("webhook doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return webhook, false
+	}
+
+	return webhook, true
+}
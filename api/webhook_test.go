@@ -0,0 +1,319 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhookAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"url":         "https://example.com/hooks",
+				"event_types": []string{db.EventTransferCreated},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhook(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateWebhookParams) (db.Webhook, error) {
+						require.Equal(t, user.Username, arg.Owner)
+						require.Equal(t, "https://example.com/hooks", arg.Url)
+						require.Equal(t, []string{db.EventTransferCreated}, arg.EventTypes)
+						require.True(t, strings.HasPrefix(arg.Secret, "whsec_"))
+
+						return db.Webhook{
+							ID:         1,
+							Owner:      arg.Owner,
+							Url:        arg.Url,
+							Secret:     arg.Secret,
+							EventTypes: arg.EventTypes,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var res createWebhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, int64(1), res.ID)
+				require.True(t, strings.HasPrefix(res.Secret, "whsec_"))
+			},
+		},
+		{
+			name: "AllEventTypes",
+			body: gin.H{
+				"url": "http://hooks.example.com:8081/hooks",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhook(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateWebhookParams) (db.Webhook, error) {
+						require.NotNil(t, arg.EventTypes)
+						require.Empty(t, arg.EventTypes)
+						return db.Webhook{ID: 1, Owner: arg.Owner, Url: arg.Url, EventTypes: arg.EventTypes}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidURL",
+			body: gin.H{
+				"url": "ftp://example.com/hooks",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PrivateHost",
+			body: gin.H{
+				"url": "http://169.254.169.254/latest/meta-data",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Localhost",
+			body: gin.H{
+				"url": "http://localhost:8081/hooks",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidEventType",
+			body: gin.H{
+				"url":         "https://example.com/hooks",
+				"event_types": []string{"account.deleted"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListWebhooksAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	webhooks := []db.Webhook{
+		randomWebhook(user.Username),
+		randomWebhook(user.Username),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListWebhooks(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(webhooks, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodGet, "/webhooks", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// the secrets are only shown when the webhooks are created
+	require.NotContains(t, recorder.Body.String(), "secret")
+	require.NotContains(t, recorder.Body.String(), webhooks[0].Secret)
+
+	var res []webhookResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Len(t, res, 2)
+	require.Equal(t, webhooks[1].Url, res[1].URL)
+}
+
+func TestReplayWebhookAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	webhook := randomWebhook(user.Username)
+
+	replayed := []db.WebhookDelivery{
+		{ID: 7, WebhookID: webhook.ID, Status: db.WebhookDeliveryPending},
+	}
+
+	testCases := []struct {
+		name          string
+		body          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "DeadDeliveries",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+				store.EXPECT().
+					ReplayWebhookDeliveries(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ReplayWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+						require.Equal(t, webhook.ID, arg.WebhookID)
+						require.Empty(t, arg.Ids)
+						require.WithinDuration(t, time.Now(), arg.Now, time.Second)
+						return replayed, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"status":"pending"`)
+			},
+		},
+		{
+			name:     "SomeDeliveries",
+			body:     `{"delivery_ids":[7]}`,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+				store.EXPECT().
+					ReplayWebhookDeliveries(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ReplayWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+						require.Equal(t, []int64{7}, arg.Ids)
+						return replayed, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidDeliveryID",
+			body:     `{"delivery_ids":[0]}`,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhook(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ReplayWebhookDeliveries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(db.Webhook{}, sql.ErrNoRows)
+				store.EXPECT().ReplayWebhookDeliveries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			username: "unauthorized",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+				store.EXPECT().ReplayWebhookDeliveries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/webhooks/%d/replay", webhook.ID)
+			req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomWebhook(owner string) db.Webhook {
+	return db.Webhook{
+		ID:         util.RandomInt(1, 1000),
+		Owner:      owner,
+		Url:        fmt.Sprintf("https://%s.example.com/hooks", util.RandomString(6)),
+		Secret:     "whsec_" + util.RandomString(32),
+		EventTypes: []string{},
+	}
+}
@@ -0,0 +1,194 @@
+package currency
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+const (
+	USD = "USD"
+	EUR = "EUR"
+	CAD = "CAD"
+)
+
+// Currency describes an ISO 4217 currency
+type Currency struct {
+	Code    string `json:"code"`
+	Numeric int    `json:"numeric"`
+	// MinorUnits is the number of decimal places, amounts are stored in these units
+	MinorUnits int `json:"minor_units"`
+}
+
+// iso4217 is the table of currencies the registry can be built from
+var iso4217 = []Currency{
+	{Code: "AUD", Numeric: 36, MinorUnits: 2},
+	{Code: "BHD", Numeric: 48, MinorUnits: 3},
+	{Code: "BRL", Numeric: 986, MinorUnits: 2},
+	{Code: CAD, Numeric: 124, MinorUnits: 2},
+	{Code: "CHF", Numeric: 756, MinorUnits: 2},
+	{Code: "CLP", Numeric: 152, MinorUnits: 0},
+	{Code: "CNY", Numeric: 156, MinorUnits: 2},
+	{Code: "CZK", Numeric: 203, MinorUnits: 2},
+	{Code: "DKK", Numeric: 208, MinorUnits: 2},
+	{Code: EUR, Numeric: 978, MinorUnits: 2},
+	{Code: "GBP", Numeric: 826, MinorUnits: 2},
+	{Code: "HKD", Numeric: 344, MinorUnits: 2},
+	{Code: "HUF", Numeric: 348, MinorUnits: 2},
+	{Code: "INR", Numeric: 356, MinorUnits: 2},
+	{Code: "ISK", Numeric: 352, MinorUnits: 0},
+	{Code: "JOD", Numeric: 400, MinorUnits: 3},
+	{Code: "JPY", Numeric: 392, MinorUnits: 0},
+	{Code: "KRW", Numeric: 410, MinorUnits: 0},
+	{Code: "KWD", Numeric: 414, MinorUnits: 3},
+	{Code: "MDL", Numeric: 498, MinorUnits: 2},
+	{Code: "MXN", Numeric: 484, MinorUnits: 2},
+	{Code: "NOK", Numeric: 578, MinorUnits: 2},
+	{Code: "NZD", Numeric: 554, MinorUnits: 2},
+	{Code: "OMR", Numeric: 512, MinorUnits: 3},
+	{Code: "PLN", Numeric: 985, MinorUnits: 2},
+	{Code: "RON", Numeric: 946, MinorUnits: 2},
+	{Code: "SEK", Numeric: 752, MinorUnits: 2},
+	{Code: "SGD", Numeric: 702, MinorUnits: 2},
+	{Code: "TND", Numeric: 788, MinorUnits: 3},
+	{Code: "TRY", Numeric: 949, MinorUnits: 2},
+	{Code: "UAH", Numeric: 980, MinorUnits: 2},
+	{Code: USD, Numeric: 840, MinorUnits: 2},
+	{Code: "ZAR", Numeric: 710, MinorUnits: 2},
+}
+
+// Registry holds the currencies the bank operates in
+type Registry struct {
+	currencies map[string]Currency
+	codes      []string
+}
+
+// NewRegistry creates a registry from the given currencies
+func NewRegistry(currencies []Currency) (*Registry, error) {
+	registry := &Registry{currencies: make(map[string]Currency, len(currencies))}
+
+	for _, cur := range currencies {
+		if len(cur.Code) != 3 {
+			return nil, fmt.Errorf("invalid currency code %q", cur.Code)
+		}
+		if cur.MinorUnits < 0 || cur.MinorUnits > maxMinorUnits {
+			return nil, fmt.Errorf("currency %s: minor units must be between 0 and %d", cur.Code, maxMinorUnits)
+		}
+		if _, ok := registry.currencies[cur.Code]; ok {
+			return nil, fmt.Errorf("duplicate currency %s", cur.Code)
+		}
+
+		registry.currencies[cur.Code] = cur
+		registry.codes = append(registry.codes, cur.Code)
+	}
+
+	sort.Strings(registry.codes)
+	return registry, nil
+}
+
+// NewISORegistry creates a registry of the given codes, taking their details from the ISO 4217 table
+func NewISORegistry(codes ...string) (*Registry, error) {
+	currencies := make([]Currency, 0, len(codes))
+
+	for _, code := range codes {
+		cur, ok := lookupISO(code)
+		if !ok {
+			return nil, fmt.Errorf("unknown ISO 4217 currency %q", code)
+		}
+		currencies = append(currencies, cur)
+	}
+
+	return NewRegistry(currencies)
+}
+
+// LoadRegistry reads a JSON list of currencies. Entries that only carry a code
+// are completed from the ISO 4217 table, e.g. [{"code": "USD"}, {"code": "XTS", "numeric": 963, "minor_units": 2}]
+func LoadRegistry(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read currencies: %w", err)
+	}
+
+	var currencies []Currency
+	if err := json.Unmarshal(data, &currencies); err != nil {
+		return nil, fmt.Errorf("cannot parse currencies: %w", err)
+	}
+
+	for i, cur := range currencies {
+		if cur.Numeric != 0 {
+			continue
+		}
+
+		iso, ok := lookupISO(cur.Code)
+		if !ok {
+			return nil, fmt.Errorf("unknown ISO 4217 currency %q", cur.Code)
+		}
+		currencies[i] = iso
+	}
+
+	return NewRegistry(currencies)
+}
+
+// Lookup returns the currency for code
+func (registry *Registry) Lookup(code string) (Currency, bool) {
+	cur, ok := registry.currencies[code]
+	return cur, ok
+}
+
+// IsSupported tells if the registry holds code
+func (registry *Registry) IsSupported(code string) bool {
+	_, ok := registry.currencies[code]
+	return ok
+}
+
+// Codes returns the sorted currency codes of the registry
+func (registry *Registry) Codes() []string {
+	codes := make([]string, len(registry.codes))
+	copy(codes, registry.codes)
+	return codes
+}
+
+func lookupISO(code string) (Currency, bool) {
+	for _, cur := range iso4217 {
+		if cur.Code == code {
+			return cur, true
+		}
+	}
+	return Currency{}, false
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultRegistry = mustISORegistry(USD, EUR, CAD)
+)
+
+// Default returns the registry used by request validation and helpers
+func Default() *Registry {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	return defaultRegistry
+}
+
+// SetDefault replaces the default registry, e.g. with one loaded from config at startup
+func SetDefault(registry *Registry) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultRegistry = registry
+}
+
+// IsSupported tells if the default registry holds code
+func IsSupported(code string) bool {
+	return Default().IsSupported(code)
+}
+
+func mustISORegistry(codes ...string) *Registry {
+	registry, err := NewISORegistry(codes...)
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}
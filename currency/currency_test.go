@@ -0,0 +1,51 @@
+package currency
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRegistry(t *testing.T) {
+	require.Equal(t, []string{CAD, EUR, USD}, Default().Codes())
+	require.True(t, IsSupported(USD))
+	require.False(t, IsSupported("JPY"))
+	require.False(t, IsSupported("usd"))
+
+	eur, ok := Default().Lookup(EUR)
+	require.True(t, ok)
+	require.Equal(t, 978, eur.Numeric)
+	require.Equal(t, 2, eur.MinorUnits)
+}
+
+func TestLoadRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "currencies.json")
+	data := `[{"code": "JPY"}, {"code": "KWD"}, {"code": "XTS", "numeric": 963, "minor_units": 2}]`
+	err := os.WriteFile(path, []byte(data), 0o600)
+	require.NoError(t, err)
+
+	registry, err := LoadRegistry(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"JPY", "KWD", "XTS"}, registry.Codes())
+
+	jpy, ok := registry.Lookup("JPY")
+	require.True(t, ok)
+	require.Equal(t, 0, jpy.MinorUnits)
+
+	kwd, ok := registry.Lookup("KWD")
+	require.True(t, ok)
+	require.Equal(t, 3, kwd.MinorUnits)
+}
+
+func TestNewRegistryRejectsInvalid(t *testing.T) {
+	_, err := NewISORegistry("XXX")
+	require.Error(t, err)
+
+	_, err = NewRegistry([]Currency{{Code: USD, MinorUnits: 2}, {Code: USD, MinorUnits: 2}})
+	require.Error(t, err)
+
+	_, err = NewRegistry([]Currency{{Code: "TOOLONG"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,152 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxMinorUnits keeps 10^MinorUnits within int64
+const maxMinorUnits = 18
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount overflows")
+)
+
+// Money is an amount expressed in the minor units of its currency, e.g. 1234 EUR cents
+type Money struct {
+	Amount   int64    `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// New creates Money from an amount in minor units
+func New(amount int64, cur Currency) Money {
+	return Money{Amount: amount, Currency: cur}
+}
+
+// String formats the money in major units, e.g. "12.34 EUR"
+func (m Money) String() string {
+	return m.Format() + " " + m.Currency.Code
+}
+
+// Format formats the amount in major units without the currency code, e.g. "-12.34"
+func (m Money) Format() string {
+	units := m.Currency.MinorUnits
+
+	// work on the unsigned magnitude so math.MinInt64 formats correctly
+	sign := ""
+	magnitude := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		magnitude = -magnitude
+	}
+
+	digits := strconv.FormatUint(magnitude, 10)
+	if units == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= units {
+		digits = strings.Repeat("0", units-len(digits)+1) + digits
+	}
+
+	split := len(digits) - units
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+// Parse reads money such as "12.34 EUR" into minor units, looking the currency up in the registry
+func (registry *Registry) Parse(s string) (Money, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Money{}, fmt.Errorf("invalid money %q: expected \"<amount> <currency>\"", s)
+	}
+
+	cur, ok := registry.Lookup(fields[1])
+	if !ok {
+		return Money{}, fmt.Errorf("unsupported currency %q", fields[1])
+	}
+
+	amount, err := ParseAmount(fields[0], cur)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return New(amount, cur), nil
+}
+
+// ParseAmount reads a decimal amount in major units, e.g. "12.34", into minor units of cur
+func ParseAmount(s string, cur Currency) (int64, error) {
+	value := s
+	negative := false
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	whole, fraction, hasPoint := strings.Cut(value, ".")
+	if whole == "" || (hasPoint && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	if len(fraction) > cur.MinorUnits {
+		return 0, fmt.Errorf("amount %q has more than %d decimal places for %s", s, cur.MinorUnits, cur.Code)
+	}
+
+	digits := whole + fraction + strings.Repeat("0", cur.MinorUnits-len(fraction))
+	magnitude, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrOverflow, s)
+	}
+
+	if negative {
+		if magnitude > uint64(math.MaxInt64)+1 {
+			return 0, fmt.Errorf("%w: %q", ErrOverflow, s)
+		}
+		return int64(-magnitude), nil
+	}
+
+	if magnitude > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %q", ErrOverflow, s)
+	}
+	return int64(magnitude), nil
+}
+
+// Add returns m + other, both must be in the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency.Code != other.Currency.Code {
+		return Money{}, fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, m.Currency.Code, other.Currency.Code)
+	}
+
+	sum := m.Amount + other.Amount
+	// overflow happened when both operands share a sign the sum doesn't have
+	if (m.Amount > 0 && other.Amount > 0 && sum < 0) || (m.Amount < 0 && other.Amount < 0 && sum >= 0) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrOverflow, m, other)
+	}
+
+	return New(sum, m.Currency), nil
+}
+
+// Sub returns m - other, both must be in the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrOverflow, m, other)
+	}
+	return m.Add(New(-other.Amount, other.Currency))
+}
+
+// IsZero tells if the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package currency
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAndFormat(t *testing.T) {
+	registry, err := NewISORegistry(EUR, "JPY", "KWD")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		input  string
+		amount int64
+		output string
+	}{
+		{"12.34 EUR", 1234, "12.34 EUR"},
+		{"12.3 EUR", 1230, "12.30 EUR"},
+		{"12 EUR", 1200, "12.00 EUR"},
+		{"0.05 EUR", 5, "0.05 EUR"},
+		{"-0.05 EUR", -5, "-0.05 EUR"},
+		{"500 JPY", 500, "500 JPY"},
+		{"1.005 KWD", 1005, "1.005 KWD"},
+	}
+
+	for _, tc := range testCases {
+		money, err := registry.Parse(tc.input)
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.amount, money.Amount, tc.input)
+		require.Equal(t, tc.output, money.String(), tc.input)
+	}
+}
+
+func TestParseRejectsInvalid(t *testing.T) {
+	registry, err := NewISORegistry(EUR, "JPY")
+	require.NoError(t, err)
+
+	for _, input := range []string{
+		"12.345 EUR",
+		"1.5 JPY",
+		"12.34",
+		"12.34 USD",
+		"abc EUR",
+		"1. EUR",
+		".5 EUR",
+		"99999999999999999999 EUR",
+	} {
+		_, err := registry.Parse(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestFormatMinInt64(t *testing.T) {
+	eur, _ := Default().Lookup(EUR)
+	require.Equal(t, "-92233720368547758.08", New(math.MinInt64, eur).Format())
+}
+
+func TestArithmetic(t *testing.T) {
+	eur, _ := Default().Lookup(EUR)
+	usd, _ := Default().Lookup(USD)
+
+	sum, err := New(150, eur).Add(New(250, eur))
+	require.NoError(t, err)
+	require.Equal(t, New(400, eur), sum)
+
+	diff, err := New(150, eur).Sub(New(250, eur))
+	require.NoError(t, err)
+	require.Equal(t, int64(-100), diff.Amount)
+
+	_, err = New(150, eur).Add(New(250, usd))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = New(math.MaxInt64, eur).Add(New(1, eur))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, eur).Sub(New(1, eur))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(0, eur).Sub(New(math.MinInt64, eur))
+	require.ErrorIs(t, err, ErrOverflow)
+}
@@ -0,0 +1,9 @@
+-- "amount" is the source leg in the sender's currency, "to_amount" the destination leg in the recipient's.
+-- "fx_rate" is the applied rate scaled by 1e8, so same-currency transfers carry 100000000.
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "fx_rate" bigint NOT NULL DEFAULT 100000000;
+
+UPDATE "transfers" SET "to_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "account_status_check" CHECK ("status" IN ('active', 'frozen', 'closed'));
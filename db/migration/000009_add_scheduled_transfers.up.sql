@@ -0,0 +1,46 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "recurrence" varchar NOT NULL DEFAULT 'once',
+  "day_of_month" int,
+  "scheduled_for" timestamptz NOT NULL,
+  "next_run_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "scheduled_transfer_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "scheduled_transfer_recurrence_check" CHECK ("recurrence" IN ('once', 'daily', 'weekly', 'monthly')),
+  CONSTRAINT "scheduled_transfer_day_of_month_check" CHECK ("day_of_month" BETWEEN 1 AND 31),
+  CONSTRAINT "scheduled_transfer_status_check" CHECK ("status" IN ('active', 'paused', 'cancelled', 'completed', 'failed'))
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "transfer_id" bigint,
+  "attempt" int NOT NULL,
+  "status" varchar NOT NULL,
+  "error" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "scheduled_transfer_run_status_check" CHECK ("status" IN ('succeeded', 'failed'))
+);
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "holds";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "available_balance";
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "account_held_amount_check";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "held_amount";
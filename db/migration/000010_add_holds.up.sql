@@ -0,0 +1,31 @@
+ALTER TABLE "accounts" ADD COLUMN "held_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "account_held_amount_check" CHECK ("held_amount" >= 0);
+
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint GENERATED ALWAYS AS ("balance" - "held_amount") STORED;
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "fx_rate" bigint NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "captured_amount" bigint NOT NULL DEFAULT 0,
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "hold_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "hold_status_check" CHECK ("status" IN ('pending', 'captured', 'voided', 'expired'))
+);
+
+CREATE INDEX ON "holds" ("from_account_id");
+
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
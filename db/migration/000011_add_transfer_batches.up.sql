@@ -0,0 +1,15 @@
+CREATE TABLE "transfer_batches" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfer_batches" ("owner");
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "transfers" ADD COLUMN "batch_id" bigint;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("batch_id") REFERENCES "transfer_batches" ("id");
+
+CREATE INDEX ON "transfers" ("batch_id");
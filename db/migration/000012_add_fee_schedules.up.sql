@@ -0,0 +1,25 @@
+ALTER TABLE "accounts" ADD COLUMN "tier" varchar NOT NULL DEFAULT 'standard';
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "fee_schedules" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "tier" varchar NOT NULL DEFAULT '',
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" int NOT NULL DEFAULT 0,
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint NOT NULL DEFAULT 0,
+  "fee_account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "fee_schedule_amounts_check" CHECK ("flat_fee" >= 0 AND "min_fee" >= 0 AND "max_fee" >= 0),
+  CONSTRAINT "fee_schedule_percentage_check" CHECK ("percentage_bps" BETWEEN 0 AND 10000)
+);
+
+COMMENT ON COLUMN "fee_schedules"."tier" IS 'empty applies to every account tier';
+
+COMMENT ON COLUMN "fee_schedules"."max_fee" IS 'zero means no cap';
+
+CREATE UNIQUE INDEX ON "fee_schedules" ("currency", "tier");
+
+ALTER TABLE "fee_schedules" ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,24 @@
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint,
+  "owner" varchar,
+  "daily_count" bigint,
+  "daily_amount" bigint,
+  "monthly_count" bigint,
+  "monthly_amount" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "transfer_limit_scope_check" CHECK ("account_id" IS NULL OR "owner" IS NULL),
+  CONSTRAINT "transfer_limit_values_check" CHECK ("daily_count" >= 0 AND "daily_amount" >= 0 AND "monthly_count" >= 0 AND "monthly_amount" >= 0)
+);
+
+COMMENT ON TABLE "transfer_limits" IS 'a row without account_id and owner holds the global defaults';
+
+COMMENT ON COLUMN "transfer_limits"."daily_count" IS 'null leaves the limit unset';
+
+CREATE UNIQUE INDEX ON "transfer_limits" ((COALESCE("account_id", 0)), (COALESCE("owner", '')));
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
@@ -0,0 +1,32 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+-- A transfer and its entries were written in one transaction, so they share created_at.
+-- Link the older entries to their transfer where that, the account and the signed amount
+-- pick out exactly one transfer; ambiguous entries are left unlinked.
+WITH "candidates" AS (
+  SELECT e."id" AS "entry_id", t."id" AS "transfer_id"
+  FROM "transfers" t
+  JOIN "entries" e ON e."created_at" = t."created_at" AND (
+    (e."account_id" = t."from_account_id" AND (e."amount" = -t."amount" OR (t."fee" > 0 AND e."amount" = -t."fee")))
+    OR (e."account_id" = t."to_account_id" AND e."amount" = t."to_amount")
+  )
+), "matches" AS (
+  SELECT "entry_id", MIN("transfer_id") AS "transfer_id"
+  FROM "candidates"
+  GROUP BY "entry_id"
+  HAVING COUNT(*) = 1
+)
+UPDATE "entries" e
+SET "transfer_id" = m."transfer_id"
+FROM "matches" m
+WHERE e."id" = m."entry_id";
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+ALTER TABLE "transfers" ADD COLUMN "fee_account_id" bigint;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,38 @@
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "request_id" varchar NOT NULL DEFAULT '',
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "operation" varchar NOT NULL,
+  "entity_type" varchar NOT NULL,
+  "entity_id" varchar NOT NULL,
+  "before" json NOT NULL DEFAULT 'null',
+  "after" json NOT NULL DEFAULT 'null',
+  "created_at" timestamptz NOT NULL,
+  "prev_hash" varchar NOT NULL,
+  "hash" varchar UNIQUE NOT NULL
+);
+
+COMMENT ON COLUMN "audit_log"."actor" IS 'username, anonymous for unauthenticated calls or system for background jobs';
+
+COMMENT ON COLUMN "audit_log"."before" IS 'json rather than jsonb keeps the hashed text as is, null for creations';
+
+COMMENT ON COLUMN "audit_log"."after" IS 'null for deletions';
+
+COMMENT ON COLUMN "audit_log"."prev_hash" IS 'hash of the previous row, empty for the first one';
+
+CREATE INDEX ON "audit_log" ("actor");
+
+CREATE INDEX ON "audit_log" ("entity_type", "entity_id");
+
+CREATE INDEX ON "audit_log" ("created_at");
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_log_append_only"
+BEFORE UPDATE OR DELETE ON "audit_log"
+FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "adjustment_id";
+
+DROP TABLE IF EXISTS "adjustments";
+
+DROP TABLE IF EXISTS "suspense_accounts";
@@ -0,0 +1,36 @@
+CREATE TABLE "suspense_accounts" (
+  "currency" varchar PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "adjustments" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "suspense_account_id" bigint NOT NULL,
+  "kind" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "reason_code" varchar NOT NULL,
+  "note" varchar NOT NULL DEFAULT '',
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "adjustment_kind_check" CHECK ("kind" IN ('deposit', 'withdrawal', 'correction'))
+);
+
+ALTER TABLE "entries" ADD COLUMN "adjustment_id" bigint;
+
+COMMENT ON COLUMN "suspense_accounts"."account_id" IS 'balancing account of the adjustments in currency';
+
+COMMENT ON COLUMN "adjustments"."amount" IS 'signed change to the balance of the account, the suspense account moves by the opposite';
+
+CREATE INDEX ON "adjustments" ("account_id");
+
+CREATE INDEX ON "entries" ("adjustment_id");
+
+ALTER TABLE "suspense_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "adjustments" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "adjustments" ADD FOREIGN KEY ("suspense_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("adjustment_id") REFERENCES "adjustments" ("id");
@@ -0,0 +1,37 @@
+-- Deleting the entries of house accounts would leave the other side of their journals unbalanced,
+-- so the ledger can only go back while the house accounts have never been posted to.
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM "entries" e
+    JOIN "accounts" a ON a."id" = e."account_id"
+    WHERE a."kind" <> 'customer'
+  ) THEN
+    RAISE EXCEPTION 'house accounts have entries, reverse their journals before migrating down';
+  END IF;
+END $$;
+
+-- the house accounts only exist since this migration, they go first with the rows that point at them
+DELETE FROM "suspense_accounts" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "kind" <> 'customer');
+
+DELETE FROM "fee_schedules" WHERE "fee_account_id" IN (SELECT "id" FROM "accounts" WHERE "kind" <> 'customer');
+
+UPDATE "transfers" SET "fee_account_id" = NULL WHERE "fee_account_id" IN (SELECT "id" FROM "accounts" WHERE "kind" <> 'customer');
+
+DELETE FROM "accounts" WHERE "kind" <> 'customer';
+
+DELETE FROM "users" WHERE "username" = 'house';
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "journal_id";
+
+DROP TABLE IF EXISTS "journals";
+
+DROP INDEX IF EXISTS "house_account_key";
+
+DROP INDEX IF EXISTS "owner_currency_key";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "account_kind_check";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "kind";
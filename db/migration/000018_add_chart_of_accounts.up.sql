@@ -0,0 +1,27 @@
+CREATE TABLE "journals" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "accounts" ADD COLUMN "kind" varchar NOT NULL DEFAULT 'customer';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "account_kind_check" CHECK ("kind" IN ('customer', 'asset', 'liability', 'revenue', 'expense', 'suspense'));
+
+COMMENT ON COLUMN "accounts"."kind" IS 'customer accounts belong to users, the others are house accounts, one per kind and currency';
+
+-- the house owns one account per kind in every currency
+ALTER TABLE "accounts" DROP CONSTRAINT "owner_currency_key";
+
+CREATE UNIQUE INDEX "owner_currency_key" ON "accounts" ("owner", "currency") WHERE "kind" = 'customer';
+
+CREATE UNIQUE INDEX "house_account_key" ON "accounts" ("currency", "kind") WHERE "kind" <> 'customer';
+
+ALTER TABLE "entries" ADD COLUMN "journal_id" bigint;
+
+CREATE INDEX ON "entries" ("journal_id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+-- '!' is not a bcrypt hash, so no password logs the house in
+INSERT INTO "users" ("username", "hash_password", "full_name", "email")
+VALUES ('house', '!', 'House accounts', 'house@simplebank.invalid');
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "interest_accruals";
+
+DROP TABLE IF EXISTS "savings_accounts";
+
+DROP TABLE IF EXISTS "account_products";
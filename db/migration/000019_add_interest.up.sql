@@ -0,0 +1,53 @@
+CREATE TABLE "account_products" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar UNIQUE NOT NULL,
+  "currency" varchar NOT NULL,
+  "rate_bps" int NOT NULL,
+  "day_count" varchar NOT NULL,
+  "compounding" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "account_product_rate_check" CHECK ("rate_bps" >= 0),
+  CONSTRAINT "account_product_day_count_check" CHECK ("day_count" IN ('ACT/365', '30/360')),
+  CONSTRAINT "account_product_compounding_check" CHECK ("compounding" IN ('monthly', 'quarterly', 'annually'))
+);
+
+CREATE TABLE "savings_accounts" (
+  "account_id" bigint PRIMARY KEY,
+  "product_id" bigint NOT NULL,
+  "accrues_from" date NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "rate_bps" int NOT NULL,
+  "day_count" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "journal_id" bigint,
+  "capitalized_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "account_products"."rate_bps" IS 'annual interest rate in basis points';
+
+COMMENT ON COLUMN "savings_accounts"."accrues_from" IS 'first day the account earns interest';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'balance at the end of the accrual date, the interest is earned on';
+
+COMMENT ON COLUMN "interest_accruals"."journal_id" IS 'null until capitalized, and for capitalized accruals of no interest';
+
+-- an account accrues once per day, so backfills can run again
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+
+CREATE INDEX ON "interest_accruals" ("account_id") WHERE "capitalized_at" IS NULL;
+
+ALTER TABLE "savings_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "savings_accounts" ADD FOREIGN KEY ("product_id") REFERENCES "account_products" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
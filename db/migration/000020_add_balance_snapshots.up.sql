@@ -0,0 +1,13 @@
+CREATE TABLE "balance_snapshots" (
+  "account_id" bigint NOT NULL,
+  "taken_at" timestamp NOT NULL,
+  "balance" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "taken_at")
+);
+
+COMMENT ON COLUMN "balance_snapshots"."taken_at" IS 'compared with entries.created_at, so of the same type';
+
+COMMENT ON COLUMN "balance_snapshots"."balance" IS 'sum of the entries of the account created before taken_at';
+
+ALTER TABLE "balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
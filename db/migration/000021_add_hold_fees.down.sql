@@ -0,0 +1 @@
+ALTER TABLE "holds" DROP COLUMN IF EXISTS "fee";
@@ -0,0 +1,3 @@
+ALTER TABLE "holds" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "holds" ADD CONSTRAINT "hold_fee_check" CHECK ("fee" >= 0);
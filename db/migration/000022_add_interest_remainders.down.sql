@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "interest_accruals" DROP COLUMN IF EXISTS "remainder";
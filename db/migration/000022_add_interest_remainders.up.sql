@@ -0,0 +1,3 @@
+ALTER TABLE "interest_accruals" ADD COLUMN "remainder" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "interest_accruals"."remainder" IS 'fraction of a minor unit of interest left over after the day, in 1/262800000, carried to the next day';
@@ -7,6 +7,16 @@ INSERT INTO accounts (
   $1, $2, $3
 ) RETURNING *;
 
+-- name: CreateHouseAccount :one
+INSERT INTO accounts (
+  owner,
+  balance,
+  currency,
+  kind
+) VALUES (
+  $1, 0, $2, $3
+) RETURNING *;
+
 -- name: GetAccount :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
@@ -16,11 +26,22 @@ SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
+-- name: GetHouseAccount :one
+SELECT * FROM accounts
+WHERE currency = $1 AND kind = $2 AND kind <> 'customer'
+LIMIT 1;
+
 -- name: ListAccounts :many
 SELECT * FROM accounts
+WHERE owner = $1
 ORDER BY id
-LIMIT $1
-OFFSET $2;
+LIMIT $2
+OFFSET $3;
+
+-- name: ListAccountsByID :many
+SELECT * FROM accounts
+WHERE id = ANY(sqlc.arg(ids)::bigint[])
+ORDER BY id;
 
 -- name: UpdateAccount :one
 UPDATE accounts 
@@ -35,5 +56,23 @@ WHERE id = sqlc.arg(id)
 RETURNING *; 
 
 
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAccountTier :one
+UPDATE accounts
+SET tier = sqlc.arg(tier)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: AddAccountHeldAmount :one
+UPDATE accounts
+SET held_amount = held_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: DeleteAccount :exec
 DELETE FROM accounts WHERE id = $1;
\ No newline at end of file
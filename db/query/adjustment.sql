@@ -0,0 +1,38 @@
+-- name: CreateAdjustment :one
+INSERT INTO adjustments (
+  account_id,
+  suspense_account_id,
+  kind,
+  amount,
+  reason_code,
+  note,
+  created_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetAdjustment :one
+SELECT * FROM adjustments
+WHERE id = $1 LIMIT 1;
+
+-- name: ListAdjustments :many
+SELECT * FROM adjustments
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: UpsertSuspenseAccount :one
+INSERT INTO suspense_accounts (
+  currency,
+  account_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (currency) DO UPDATE SET
+  account_id = EXCLUDED.account_id
+RETURNING *;
+
+-- name: GetSuspenseAccount :one
+SELECT * FROM suspense_accounts
+WHERE currency = $1 LIMIT 1;
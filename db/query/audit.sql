@@ -0,0 +1,44 @@
+-- name: LockAuditLog :exec
+-- Serializes appends to the hash chain until the transaction ends.
+SELECT pg_advisory_xact_lock(hashtext('audit_log'));
+
+-- name: GetLastAuditLogHash :one
+SELECT hash FROM audit_log
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (
+  actor,
+  request_id,
+  client_ip,
+  operation,
+  entity_type,
+  entity_id,
+  before,
+  after,
+  created_at,
+  prev_hash,
+  hash
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: ListAuditLog :many
+-- Empty filters and null times match every row.
+SELECT * FROM audit_log
+WHERE (sqlc.arg(actor)::varchar = '' OR actor = sqlc.arg(actor))
+  AND (sqlc.arg(operation)::varchar = '' OR operation = sqlc.arg(operation))
+  AND (sqlc.arg(entity_type)::varchar = '' OR entity_type = sqlc.arg(entity_type))
+  AND (sqlc.arg(entity_id)::varchar = '' OR entity_id = sqlc.arg(entity_id))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+ORDER BY id DESC
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: ListAuditLogAfter :many
+SELECT * FROM audit_log
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
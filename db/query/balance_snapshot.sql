@@ -0,0 +1,68 @@
+-- name: CreateBalanceSnapshots :many
+-- Snapshots the next limit_count accounts after after_id that existed at taken_at. Each balance
+-- starts from the snapshot before, so snapshots must be taken in order of time.
+INSERT INTO balance_snapshots (
+  account_id,
+  taken_at,
+  balance
+)
+SELECT
+  a.id,
+  sqlc.arg(taken_at),
+  (COALESCE(s.balance, 0) + (
+    SELECT COALESCE(SUM(e.amount), 0) FROM entries e
+    WHERE e.account_id = a.id
+      AND e.created_at >= COALESCE(s.taken_at, '-infinity')
+      AND e.created_at < sqlc.arg(taken_at)
+  ))::bigint
+FROM accounts a
+LEFT JOIN LATERAL (
+  SELECT p.taken_at, p.balance FROM balance_snapshots p
+  WHERE p.account_id = a.id AND p.taken_at < sqlc.arg(taken_at)
+  ORDER BY p.taken_at DESC
+  LIMIT 1
+) s ON true
+WHERE a.id > sqlc.arg(after_id) AND a.created_at < sqlc.arg(taken_at)
+ORDER BY a.id
+LIMIT sqlc.arg(limit_count)
+ON CONFLICT (account_id, taken_at) DO UPDATE SET
+  balance = EXCLUDED.balance
+RETURNING account_id;
+
+-- name: ListBalanceSnapshotTimes :many
+SELECT DISTINCT taken_at FROM balance_snapshots
+WHERE taken_at >= sqlc.arg(from_time)
+ORDER BY taken_at;
+
+-- name: GetBalanceAt :one
+-- The balance of the entries created before as_of, summed from the last snapshot taken by then.
+SELECT
+  (COALESCE(s.balance, 0) + (
+    SELECT COALESCE(SUM(e.amount), 0) FROM entries e
+    WHERE e.account_id = sqlc.arg(account_id)
+      AND e.created_at >= COALESCE(s.taken_at, '-infinity')
+      AND e.created_at < sqlc.arg(as_of)
+  ))::bigint AS balance,
+  s.taken_at AS snapshot_taken_at
+FROM (SELECT 1) one
+LEFT JOIN LATERAL (
+  SELECT p.taken_at, p.balance FROM balance_snapshots p
+  WHERE p.account_id = sqlc.arg(account_id) AND p.taken_at <= sqlc.arg(as_of)
+  ORDER BY p.taken_at DESC
+  LIMIT 1
+) s ON true;
+
+-- name: GetSnapshotCutoff :one
+-- The latest time a snapshot can be taken at: entries are dated when their transaction starts,
+-- so the ones dated before the start of a transaction still open may not be committed yet.
+SELECT LEAST(now(), COALESCE(MIN(a.xact_start), now()))::timestamp AS cutoff
+FROM pg_stat_activity a
+WHERE a.datname = current_database()
+  AND a.pid <> pg_backend_pid()
+  AND a.xact_start IS NOT NULL;
+
+-- name: ShiftBalanceSnapshots :exec
+-- Adds the amount of an entry dated before snapshots already taken to each of them.
+UPDATE balance_snapshots
+SET balance = balance + sqlc.arg(amount)
+WHERE account_id = sqlc.arg(account_id) AND taken_at > sqlc.arg(dated_at);
@@ -1,9 +1,19 @@
 -- name: CreateEntry :one
+-- Entries are dated now, unless created_at dates them back.
 INSERT INTO entries (
   account_id, 
-  amount
+  amount,
+  transfer_id,
+  adjustment_id,
+  journal_id,
+  created_at
 ) VALUES (
-  $1, $2
+  sqlc.arg(account_id),
+  sqlc.arg(amount),
+  sqlc.arg(transfer_id),
+  sqlc.arg(adjustment_id),
+  sqlc.arg(journal_id),
+  COALESCE(sqlc.narg(created_at)::timestamp, now())
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -16,3 +26,56 @@ WHERE account_id = $1
 ORDER BY id
 LIMIT $2
 OFFSET $3;
+
+-- name: ListTransferEntries :many
+SELECT * FROM entries
+WHERE transfer_id = $1
+ORDER BY id;
+
+-- name: ListJournalEntries :many
+SELECT * FROM entries
+WHERE journal_id = $1
+ORDER BY id;
+
+-- name: GetStatementBalances :one
+SELECT
+  COALESCE(SUM(amount) FILTER (WHERE created_at < sqlc.arg(from_time)), 0)::bigint AS opening_balance,
+  COALESCE(SUM(amount), 0)::bigint AS closing_balance
+FROM entries
+WHERE account_id = sqlc.arg(account_id) AND created_at < sqlc.arg(to_time);
+
+-- name: ListStatementEntries :many
+-- A transfer posts its amount pair first and then its fee pair, the fee entries
+-- have the house fee account as counterparty. The asset house accounts the legs of
+-- a cross-currency transfer go through have the customer on the other side as counterparty.
+-- An adjustment is balanced by the suspense account, each is the counterparty of the other.
+WITH opening AS (
+  SELECT COALESCE(SUM(amount), 0)::bigint AS balance
+  FROM entries
+  WHERE account_id = sqlc.arg(account_id) AND created_at < sqlc.arg(from_time)
+)
+SELECT
+  e.id,
+  e.amount,
+  e.transfer_id,
+  e.created_at,
+  CASE
+    WHEN ad.id IS NOT NULL THEN
+      CASE WHEN e.account_id = ad.account_id THEN ad.suspense_account_id ELSE ad.account_id END
+    WHEN t.id IS NULL THEN NULL
+    WHEN e.account_id NOT IN (t.from_account_id, t.to_account_id, COALESCE(t.fee_account_id, 0)) THEN
+      CASE WHEN e.amount < 0 THEN t.to_account_id ELSE t.from_account_id END
+    WHEN (SELECT COUNT(*) FROM entries e2 WHERE e2.transfer_id = t.id AND e2.id < e.id) >= 2 THEN
+      CASE WHEN e.amount < 0 THEN t.fee_account_id ELSE t.from_account_id END
+    WHEN e.amount < 0 THEN t.to_account_id
+    ELSE t.from_account_id
+  END AS counterparty_account_id,
+  (opening.balance + SUM(e.amount) OVER (ORDER BY e.created_at, e.id))::bigint AS running_balance
+FROM entries e
+CROSS JOIN opening
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN adjustments ad ON ad.id = e.adjustment_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+ORDER BY e.created_at, e.id;
@@ -0,0 +1,29 @@
+-- name: UpsertFeeSchedule :one
+INSERT INTO fee_schedules (
+  currency,
+  tier,
+  flat_fee,
+  percentage_bps,
+  min_fee,
+  max_fee,
+  fee_account_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (currency, tier) DO UPDATE SET
+  flat_fee = EXCLUDED.flat_fee,
+  percentage_bps = EXCLUDED.percentage_bps,
+  min_fee = EXCLUDED.min_fee,
+  max_fee = EXCLUDED.max_fee,
+  fee_account_id = EXCLUDED.fee_account_id
+RETURNING *;
+
+-- name: GetFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE currency = sqlc.arg(currency) AND tier IN (sqlc.arg(tier)::varchar, '')
+ORDER BY tier DESC
+LIMIT 1;
+
+-- name: ListFeeSchedules :many
+SELECT * FROM fee_schedules
+ORDER BY currency, tier;
@@ -0,0 +1,41 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+  from_account_id,
+  to_account_id,
+  amount,
+  to_amount,
+  fx_rate,
+  fee,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetExpiredHold :one
+SELECT * FROM holds
+WHERE status = 'pending' AND expires_at <= $1
+ORDER BY expires_at
+LIMIT 1
+FOR NO KEY UPDATE SKIP LOCKED;
+
+-- name: CountPendingHolds :one
+SELECT COUNT(*) FROM holds
+WHERE status = 'pending' AND (from_account_id = $1 OR to_account_id = $1);
+
+-- name: UpdateHold :one
+UPDATE holds
+SET
+  status = sqlc.arg(status),
+  captured_amount = sqlc.arg(captured_amount),
+  transfer_id = sqlc.arg(transfer_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
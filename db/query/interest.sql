@@ -0,0 +1,101 @@
+-- name: UpsertAccountProduct :one
+INSERT INTO account_products (
+  name,
+  currency,
+  rate_bps,
+  day_count,
+  compounding
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (name) DO UPDATE SET
+  currency = EXCLUDED.currency,
+  rate_bps = EXCLUDED.rate_bps,
+  day_count = EXCLUDED.day_count,
+  compounding = EXCLUDED.compounding
+RETURNING *;
+
+-- name: GetAccountProduct :one
+SELECT * FROM account_products
+WHERE id = $1 LIMIT 1;
+
+-- name: CreateSavingsAccount :one
+INSERT INTO savings_accounts (
+  account_id,
+  product_id,
+  accrues_from
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetSavingsAccount :one
+SELECT * FROM savings_accounts
+WHERE account_id = $1 LIMIT 1;
+
+-- name: ListSavingsAccounts :many
+-- Accounts accrue from the day after their last accrual, closed accounts no longer do.
+-- uncapitalized_from is the first day accrued and not capitalized yet, or next_accrual_date if there is none.
+-- interest_remainder is the fraction of a minor unit the last accrual carries over.
+SELECT
+  s.account_id,
+  p.rate_bps,
+  p.day_count,
+  p.compounding,
+  COALESCE(
+    (SELECT MAX(i.accrual_date) + 1 FROM interest_accruals i WHERE i.account_id = s.account_id),
+    s.accrues_from
+  )::date AS next_accrual_date,
+  COALESCE(
+    (SELECT MIN(i.accrual_date) FROM interest_accruals i WHERE i.account_id = s.account_id AND i.capitalized_at IS NULL),
+    (SELECT MAX(i.accrual_date) + 1 FROM interest_accruals i WHERE i.account_id = s.account_id),
+    s.accrues_from
+  )::date AS uncapitalized_from,
+  COALESCE(
+    (SELECT i.remainder FROM interest_accruals i WHERE i.account_id = s.account_id ORDER BY i.accrual_date DESC LIMIT 1),
+    0
+  )::bigint AS interest_remainder
+FROM savings_accounts s
+JOIN account_products p ON p.id = s.product_id
+JOIN accounts a ON a.id = s.account_id
+WHERE s.account_id > sqlc.arg(after_id) AND a.status <> 'closed'
+ORDER BY s.account_id
+LIMIT sqlc.arg(limit_count);
+
+-- name: CreateInterestAccrual :execrows
+-- An account accrues once per day, a day accrued already is left alone.
+INSERT INTO interest_accruals (
+  account_id,
+  accrual_date,
+  balance,
+  rate_bps,
+  day_count,
+  amount,
+  remainder
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- name: ListUncapitalizedAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id)
+  AND accrual_date <= sqlc.arg(through)::date
+  AND capitalized_at IS NULL
+ORDER BY accrual_date
+FOR NO KEY UPDATE;
+
+-- name: CapitalizeInterestAccruals :execrows
+UPDATE interest_accruals
+SET
+  journal_id = sqlc.narg(journal_id),
+  capitalized_at = now()
+WHERE account_id = sqlc.arg(account_id)
+  AND accrual_date <= sqlc.arg(through)::date
+  AND capitalized_at IS NULL;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date DESC
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,24 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+  event_type,
+  owners,
+  payload
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetOutboxEvent :one
+SELECT * FROM outbox
+WHERE id = $1 LIMIT 1;
+
+-- name: ListPendingOutboxEvents :many
+SELECT * FROM outbox
+WHERE dispatched_at IS NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventDispatched :exec
+UPDATE outbox
+SET dispatched_at = now()
+WHERE id = $1;
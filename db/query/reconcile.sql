@@ -0,0 +1,38 @@
+-- name: ListAccountEntryTotals :many
+SELECT
+  a.id,
+  a.balance,
+  (SELECT COALESCE(SUM(e.amount), 0) FROM entries e WHERE e.account_id = a.id)::bigint AS entries_total
+FROM accounts a
+WHERE a.id > sqlc.arg(after_id)
+ORDER BY a.id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListUnmatchedTransfers :many
+SELECT
+  t.id,
+  t.from_account_id,
+  t.to_account_id,
+  (CASE WHEN t.fee > 0 THEN 2 ELSE 1 END)::bigint AS expected_debits,
+  COUNT(e.id) FILTER (
+    WHERE e.account_id = t.from_account_id
+      AND (e.amount = -t.amount OR (t.fee > 0 AND e.amount = -t.fee))
+  )::bigint AS debit_entries,
+  1::bigint AS expected_credits,
+  COUNT(e.id) FILTER (
+    WHERE e.account_id = t.to_account_id
+      AND e.amount = t.to_amount
+  )::bigint AS credit_entries
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+WHERE t.from_account_id BETWEEN sqlc.arg(first_account_id) AND sqlc.arg(last_account_id)
+GROUP BY t.id
+HAVING COUNT(e.id) FILTER (
+    WHERE e.account_id = t.from_account_id
+      AND (e.amount = -t.amount OR (t.fee > 0 AND e.amount = -t.fee))
+  ) <> (CASE WHEN t.fee > 0 THEN 2 ELSE 1 END)
+  OR COUNT(e.id) FILTER (
+    WHERE e.account_id = t.to_account_id
+      AND e.amount = t.to_amount
+  ) <> 1
+ORDER BY t.id;
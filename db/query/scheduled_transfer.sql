@@ -0,0 +1,75 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  recurrence,
+  day_of_month,
+  scheduled_for,
+  next_run_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $7
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetDueScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE status = 'active' AND next_run_at <= $1
+ORDER BY next_run_at
+LIMIT 1
+FOR NO KEY UPDATE SKIP LOCKED;
+
+-- name: CountOpenScheduledTransfers :one
+SELECT COUNT(*) FROM scheduled_transfers
+WHERE status IN ('active', 'paused') AND (from_account_id = $1 OR to_account_id = $1);
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateScheduledTransferStatus :one
+UPDATE scheduled_transfers
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: RescheduleScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  status = sqlc.arg(status),
+  scheduled_for = sqlc.arg(scheduled_for),
+  next_run_at = sqlc.arg(next_run_at),
+  attempts = sqlc.arg(attempts),
+  last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+  scheduled_transfer_id,
+  transfer_id,
+  attempt,
+  status,
+  error
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
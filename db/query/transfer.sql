@@ -2,15 +2,31 @@
 INSERT INTO transfers (
   from_account_id, 
   to_account_id,
-  amount
+  amount,
+  reversal_of,
+  to_amount,
+  fx_rate,
+  batch_id,
+  fee,
+  fee_account_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(to_amount), 0)::bigint AS reversed_amount
+FROM transfers
+WHERE reversal_of = $1;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
 WHERE 
@@ -18,4 +34,9 @@ WHERE
   to_account_id = $2
 ORDER BY id
 LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- name: ListBatchTransfers :many
+SELECT * FROM transfers
+WHERE batch_id = $1
+ORDER BY id;
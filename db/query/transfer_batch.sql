@@ -0,0 +1,10 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (
+  owner
+) VALUES (
+  $1
+) RETURNING *;
+
+-- name: GetTransferBatch :one
+SELECT * FROM transfer_batches
+WHERE id = $1 LIMIT 1;
@@ -0,0 +1,42 @@
+-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (
+  account_id,
+  owner,
+  daily_count,
+  daily_amount,
+  monthly_count,
+  monthly_amount
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT ((COALESCE(account_id, 0)), (COALESCE(owner, ''))) DO UPDATE SET
+  daily_count = EXCLUDED.daily_count,
+  daily_amount = EXCLUDED.daily_amount,
+  monthly_count = EXCLUDED.monthly_count,
+  monthly_amount = EXCLUDED.monthly_amount
+RETURNING *;
+
+-- name: ListApplicableTransferLimits :many
+SELECT * FROM transfer_limits
+WHERE account_id = sqlc.arg(account_id)::bigint
+  OR owner = sqlc.arg(owner)::varchar
+  OR (account_id IS NULL AND owner IS NULL);
+
+-- name: GetAccountTransferTotals :one
+SELECT
+  COUNT(*)::bigint AS count,
+  COALESCE(SUM(amount), 0)::bigint AS amount
+FROM transfers
+WHERE from_account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(since)
+  AND reversal_of IS NULL;
+
+-- name: GetOwnerTransferTotals :one
+SELECT
+  COUNT(*)::bigint AS count,
+  COALESCE(SUM(t.amount) FILTER (WHERE a.currency = sqlc.arg(currency)), 0)::bigint AS amount
+FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+WHERE a.owner = sqlc.arg(owner)
+  AND t.created_at >= sqlc.arg(since)
+  AND t.reversal_of IS NULL;
@@ -0,0 +1,90 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (
+  owner,
+  url,
+  secret,
+  event_types
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhooks :many
+SELECT * FROM webhooks
+WHERE owner = $1
+ORDER BY id;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE id = $1;
+
+-- name: ListEventWebhooks :many
+SELECT * FROM webhooks
+WHERE owner = ANY(sqlc.arg(owners)::varchar[])
+  AND (cardinality(event_types) = 0 OR sqlc.arg(event_type)::varchar = ANY(event_types))
+ORDER BY id;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (
+  webhook_id,
+  event_id
+) VALUES (
+  $1, $2
+) ON CONFLICT (webhook_id, event_id) DO NOTHING;
+
+-- name: ClaimDueWebhookDelivery :one
+-- Claiming counts the attempt and leases the delivery until lease_until,
+-- it is retried from then on if the worker never reports back.
+UPDATE webhook_deliveries d
+SET
+  attempts = d.attempts + 1,
+  next_attempt_at = sqlc.arg(lease_until)
+FROM webhooks w, outbox o
+WHERE d.id = (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= sqlc.arg(now)
+    ORDER BY next_attempt_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+  )
+  AND w.id = d.webhook_id
+  AND o.id = d.event_id
+RETURNING d.id, d.webhook_id, d.event_id, d.attempts, w.url, w.secret, o.event_type, o.payload, o.created_at AS event_created_at;
+
+-- name: UpdateWebhookDelivery :one
+UPDATE webhook_deliveries
+SET
+  status = sqlc.arg(status),
+  next_attempt_at = sqlc.arg(next_attempt_at),
+  last_error = sqlc.arg(last_error),
+  delivered_at = sqlc.arg(delivered_at)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = sqlc.arg(webhook_id)
+  AND (sqlc.arg(status)::varchar = '' OR status = sqlc.arg(status))
+ORDER BY id DESC
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: ReplayWebhookDeliveries :many
+-- No ids replays every dead delivery of the webhook. Pending deliveries are left alone.
+UPDATE webhook_deliveries
+SET
+  status = 'pending',
+  attempts = 0,
+  next_attempt_at = sqlc.arg(now),
+  last_error = NULL,
+  delivered_at = NULL
+WHERE webhook_id = sqlc.arg(webhook_id)
+  AND status <> 'pending'
+  AND (
+    (cardinality(sqlc.arg(ids)::bigint[]) = 0 AND status = 'dead')
+    OR id = ANY(sqlc.arg(ids)::bigint[])
+  )
+RETURNING *;
@@ -7,8 +7,41 @@ package db
 
 import (
 	"context"
+
+	"github.com/lib/pq"
 )
 
+const addAccountHeldAmount = `-- name: AddAccountHeldAmount :one
+UPDATE accounts
+SET held_amount = held_amount + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind
+`
+
+type AddAccountHeldAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeldAmount, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Tier,
+		&i.Kind,
+	)
+	return i, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
   owner, 
@@ -16,7 +49,7 @@ INSERT INTO accounts (
   currency
 ) VALUES (
   $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+) RETURNING id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind
 `
 
 type CreateAccountParams struct {
@@ -34,6 +67,48 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Tier,
+		&i.Kind,
+	)
+	return i, err
+}
+
+const createHouseAccount = `-- name: CreateHouseAccount :one
+INSERT INTO accounts (
+  owner,
+  balance,
+  currency,
+  kind
+) VALUES (
+  $1, 0, $2, $3
+) RETURNING id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind
+`
+
+type CreateHouseAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+	Kind     string `json:"kind"`
+}
+
+func (q *Queries) CreateHouseAccount(ctx context.Context, arg CreateHouseAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, createHouseAccount, arg.Owner, arg.Currency, arg.Kind)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Tier,
+		&i.Kind,
 	)
 	return i, err
 }
@@ -48,7 +123,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -61,12 +136,18 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Tier,
+		&i.Kind,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -80,24 +161,62 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Tier,
+		&i.Kind,
+	)
+	return i, err
+}
+
+const getHouseAccount = `-- name: GetHouseAccount :one
+SELECT id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind FROM accounts
+WHERE currency = $1 AND kind = $2 AND kind <> 'customer'
+LIMIT 1
+`
+
+type GetHouseAccountParams struct {
+	Currency string `json:"currency"`
+	Kind     string `json:"kind"`
+}
+
+func (q *Queries) GetHouseAccount(ctx context.Context, arg GetHouseAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getHouseAccount, arg.Currency, arg.Kind)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Tier,
+		&i.Kind,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind FROM accounts
+WHERE owner = $1
 ORDER BY id
-LIMIT $1
-OFFSET $2
+LIMIT $2
+OFFSET $3
 `
 
 type ListAccountsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +230,53 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.Status,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.Tier,
+			&i.Kind,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsByID = `-- name: ListAccountsByID :many
+SELECT id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind FROM accounts
+WHERE id = ANY($1::bigint[])
+ORDER BY id
+`
+
+func (q *Queries) ListAccountsByID(ctx context.Context, ids []int64) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsByID, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.Status,
+			&i.HeldAmount,
+			&i.AvailableBalance,
+			&i.Tier,
+			&i.Kind,
 		); err != nil {
 			return nil, err
 		}
@@ -129,7 +295,7 @@ const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts 
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind
 `
 
 type UpdateAccountParams struct {
@@ -146,6 +312,12 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Tier,
+		&i.Kind,
 	)
 	return i, err
 }
@@ -154,7 +326,7 @@ const updateAccountBalance = `-- name: UpdateAccountBalance :one
 UPDATE accounts 
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind
 `
 
 type UpdateAccountBalanceParams struct {
@@ -171,6 +343,74 @@ func (q *Queries) UpdateAccountBalance(ctx context.Context, arg UpdateAccountBal
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Tier,
+		&i.Kind,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind
+`
+
+type UpdateAccountStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountStatus, arg.Status, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Tier,
+		&i.Kind,
+	)
+	return i, err
+}
+
+const updateAccountTier = `-- name: UpdateAccountTier :one
+UPDATE accounts
+SET tier = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, status, held_amount, available_balance, tier, kind
+`
+
+type UpdateAccountTierParams struct {
+	Tier string `json:"tier"`
+	ID   int64  `json:"id"`
+}
+
+func (q *Queries) UpdateAccountTier(ctx context.Context, arg UpdateAccountTierParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountTier, arg.Tier, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldAmount,
+		&i.AvailableBalance,
+		&i.Tier,
+		&i.Kind,
 	)
 	return i, err
 }
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Account statuses, an account only transacts while active
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+var (
+	ErrAccountFrozen = errors.New("account is frozen")
+	ErrAccountClosed = errors.New("account is closed")
+)
+
+// ErrInvalidStatusTransition is matched by every InvalidStatusTransitionError
+var ErrInvalidStatusTransition = errors.New("invalid account status transition")
+
+// InvalidStatusTransitionError is returned when an account can't move from its status to the requested one
+type InvalidStatusTransitionError struct {
+	AccountID int64
+	From      string
+	To        string
+}
+
+func (e *InvalidStatusTransitionError) Error() string {
+	return fmt.Sprintf("account [%d] can't go from %s to %s", e.AccountID, e.From, e.To)
+}
+
+func (e *InvalidStatusTransitionError) Is(target error) bool {
+	return target == ErrInvalidStatusTransition
+}
+
+// ErrAccountNotEmpty is matched by every AccountNotEmptyError
+var ErrAccountNotEmpty = errors.New("account balance is not zero")
+
+// AccountNotEmptyError is returned when closing an account that still holds money
+type AccountNotEmptyError struct {
+	AccountID int64
+	Balance   int64
+}
+
+func (e *AccountNotEmptyError) Error() string {
+	return fmt.Sprintf("account [%d] still has a balance of %d", e.AccountID, e.Balance)
+}
+
+func (e *AccountNotEmptyError) Is(target error) bool {
+	return target == ErrAccountNotEmpty
+}
+
+// ErrAccountInUse is matched by every AccountInUseError
+var ErrAccountInUse = errors.New("account has outstanding commitments")
+
+// AccountInUseError is returned when closing an account that still has money on hold,
+// pending holds or scheduled transfers that haven't ended
+type AccountInUseError struct {
+	AccountID          int64
+	HeldAmount         int64
+	PendingHolds       int64
+	ScheduledTransfers int64
+}
+
+func (e *AccountInUseError) Error() string {
+	return fmt.Sprintf("account [%d] still has %d held, %d pending holds and %d scheduled transfers",
+		e.AccountID, e.HeldAmount, e.PendingHolds, e.ScheduledTransfers)
+}
+
+func (e *AccountInUseError) Is(target error) bool {
+	return target == ErrAccountInUse
+}
+
+// allowedStatusTransitions maps a status to the ones it can move to. Closing is final.
+var allowedStatusTransitions = map[string][]string{
+	AccountStatusActive: {AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusFrozen: {AccountStatusActive, AccountStatusClosed},
+}
+
+type ChangeAccountStatusTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+}
+
+// ChangeAccountStatusTx freezes, unfreezes or closes an account.
+// The account row is locked, so the checks for closing can't race with transfers or new holds.
+func (store *SQLStore) ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (Account, error) {
+	var acc Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		acc, err = q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if !canTransition(acc.Status, arg.Status) {
+			return &InvalidStatusTransitionError{
+				AccountID: acc.ID,
+				From:      acc.Status,
+				To:        arg.Status,
+			}
+		}
+
+		if arg.Status == AccountStatusClosed {
+			if err := checkAccountClosable(ctx, q, acc); err != nil {
+				return err
+			}
+		}
+
+		before := acc
+		acc, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     arg.AccountID,
+			Status: arg.Status,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditAccountStatusChange, acc.ID, before, acc)
+	})
+
+	return acc, err
+}
+
+// checkAccountClosable refuses to close an account that still has a balance,
+// money on hold, pending holds or active or paused scheduled transfers
+func checkAccountClosable(ctx context.Context, q *Queries, acc Account) error {
+	if acc.Balance != 0 {
+		return &AccountNotEmptyError{
+			AccountID: acc.ID,
+			Balance:   acc.Balance,
+		}
+	}
+
+	pendingHolds, err := q.CountPendingHolds(ctx, acc.ID)
+	if err != nil {
+		return err
+	}
+
+	scheduledTransfers, err := q.CountOpenScheduledTransfers(ctx, acc.ID)
+	if err != nil {
+		return err
+	}
+
+	if acc.HeldAmount > 0 || pendingHolds > 0 || scheduledTransfers > 0 {
+		return &AccountInUseError{
+			AccountID:          acc.ID,
+			HeldAmount:         acc.HeldAmount,
+			PendingHolds:       pendingHolds,
+			ScheduledTransfers: scheduledTransfers,
+		}
+	}
+
+	return nil
+}
+
+func canTransition(from string, to string) bool {
+	for _, allowed := range allowedStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAccountActive returns ErrAccountFrozen or ErrAccountClosed for accounts that can't transact
+func checkAccountActive(acc Account) error {
+	switch acc.Status {
+	case AccountStatusFrozen:
+		return fmt.Errorf("%w: account [%d]", ErrAccountFrozen, acc.ID)
+	case AccountStatusClosed:
+		return fmt.Errorf("%w: account [%d]", ErrAccountClosed, acc.ID)
+	}
+	return nil
+}
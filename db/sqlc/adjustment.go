@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Kinds of adjustments. Deposits and withdrawals take a positive amount,
+// corrections a signed one that is added to the balance.
+const (
+	AdjustmentDeposit    = "deposit"
+	AdjustmentWithdrawal = "withdrawal"
+	AdjustmentCorrection = "correction"
+)
+
+// adjustmentReasons lists the reason codes allowed for each kind of adjustment
+var adjustmentReasons = map[string][]string{
+	AdjustmentDeposit:    {"cash", "cheque", "wire"},
+	AdjustmentWithdrawal: {"cash", "cheque", "wire"},
+	AdjustmentCorrection: {"posting_error", "duplicate_posting", "bank_error", "goodwill"},
+}
+
+// ErrInvalidAdjustment is returned for an adjustment whose amount or reason code doesn't fit its kind
+var ErrInvalidAdjustment = errors.New("invalid adjustment")
+
+// ErrNoSuspenseAccount is returned when no suspense account is set up for the currency of an adjustment
+var ErrNoSuspenseAccount = errors.New("no suspense account for currency")
+
+type AdjustAccountTxParams struct {
+	AccountID  int64  `json:"account_id"`
+	Kind       string `json:"kind"`
+	Amount     int64  `json:"amount"`
+	ReasonCode string `json:"reason_code"`
+	Note       string `json:"note"`
+	// CreatedBy is the user who made the adjustment
+	CreatedBy string `json:"created_by"`
+}
+
+type AdjustAccountTxResult struct {
+	Adjustment    Adjustment `json:"adjustment"`
+	Account       Account    `json:"account"`
+	Entry         Entry      `json:"entry"`
+	SuspenseEntry Entry      `json:"suspense_entry"`
+}
+
+// AdjustAccountTx deposits to, withdraws from or corrects the balance of an account.
+// The adjustment posts an entry to the account and the opposite one to the suspense account
+// of its currency, so the ledger stays balanced. Withdrawals are held to the available funds,
+// corrections aren't, and frozen accounts only take corrections.
+func (store *SQLStore) AdjustAccountTx(ctx context.Context, arg AdjustAccountTxParams) (AdjustAccountTxResult, error) {
+	var res AdjustAccountTxResult
+
+	delta, err := adjustmentDelta(arg)
+	if err != nil {
+		return res, err
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		acc, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		suspense, err := q.GetSuspenseAccount(ctx, acc.Currency)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w %s", ErrNoSuspenseAccount, acc.Currency)
+			}
+			return err
+		}
+
+		if suspense.AccountID == acc.ID {
+			return fmt.Errorf("%w: account [%d] is the suspense account", ErrInvalidAdjustment, acc.ID)
+		}
+
+		accounts, err := lockAccountsInOrder(ctx, q, acc.ID, suspense.AccountID)
+		if err != nil {
+			return err
+		}
+
+		acc = accounts[acc.ID]
+		if acc.Status != AccountStatusFrozen || arg.Kind != AdjustmentCorrection {
+			if err := checkAccountActive(acc); err != nil {
+				return err
+			}
+		}
+
+		if arg.Kind == AdjustmentWithdrawal {
+			if err := checkFunds(acc, -delta); err != nil {
+				return err
+			}
+		}
+
+		res.Adjustment, err = q.CreateAdjustment(ctx, CreateAdjustmentParams{
+			AccountID:         acc.ID,
+			SuspenseAccountID: suspense.AccountID,
+			Kind:              arg.Kind,
+			Amount:            delta,
+			ReasonCode:        arg.ReasonCode,
+			Note:              arg.Note,
+			CreatedBy:         arg.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		postings := []Posting{
+			{AccountID: acc.ID, Amount: delta},
+			{AccountID: suspense.AccountID, Amount: -delta},
+		}
+
+		entries, err := postEntries(ctx, q, accounts, postings, CreateEntryParams{
+			AdjustmentID: sql.NullInt64{Int64: res.Adjustment.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		res.Entry, res.SuspenseEntry = entries[0], entries[1]
+		res.Account = accounts[acc.ID]
+
+		deltas := postingDeltas(postings)
+		err = publishBalanceChanges(ctx, q, accounts, deltas, AccountBalanceChangedEvent{AdjustmentID: res.Adjustment.ID})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditAdjustmentCreate, res.Adjustment.ID, nil, res.Adjustment)
+	})
+
+	return res, err
+}
+
+// adjustmentDelta checks the amount and reason code of an adjustment against its kind
+// and returns the change it makes to the balance of the account
+func adjustmentDelta(arg AdjustAccountTxParams) (int64, error) {
+	reasons, ok := adjustmentReasons[arg.Kind]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown kind %q", ErrInvalidAdjustment, arg.Kind)
+	}
+
+	if !containsString(reasons, arg.ReasonCode) {
+		return 0, fmt.Errorf("%w: reason code %q doesn't apply to a %s", ErrInvalidAdjustment, arg.ReasonCode, arg.Kind)
+	}
+
+	switch {
+	case arg.Kind == AdjustmentCorrection && arg.Amount != 0:
+		return arg.Amount, nil
+	case arg.Kind == AdjustmentDeposit && arg.Amount > 0:
+		return arg.Amount, nil
+	case arg.Kind == AdjustmentWithdrawal && arg.Amount > 0:
+		return -arg.Amount, nil
+	}
+
+	return 0, fmt.Errorf("%w: amount %d for a %s", ErrInvalidAdjustment, arg.Amount, arg.Kind)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,284 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Operations recorded in the audit log, the part before the dot is the type of the entity changed
+const (
+	AuditAccountCreate                 = "account.create"
+	AuditAccountStatusChange           = "account.status_change"
+	AuditTransferCreate                = "transfer.create"
+	AuditTransferBatchCreate           = "transfer_batch.create"
+	AuditHoldAuthorize                 = "hold.authorize"
+	AuditHoldCapture                   = "hold.capture"
+	AuditHoldVoid                      = "hold.void"
+	AuditHoldExpire                    = "hold.expire"
+	AuditScheduledTransferCreate       = "scheduled_transfer.create"
+	AuditScheduledTransferStatusChange = "scheduled_transfer.status_change"
+	AuditScheduledTransferRun          = "scheduled_transfer.run"
+	AuditUserCreate                    = "user.create"
+	AuditUserSessionsBlock             = "user.sessions_block"
+	AuditSessionCreate                 = "session.create"
+	AuditSessionBlock                  = "session.block"
+	AuditWebhookCreate                 = "webhook.create"
+	AuditWebhookDelete                 = "webhook.delete"
+	AuditWebhookReplay                 = "webhook.replay"
+	AuditFeeScheduleUpsert             = "fee_schedule.upsert"
+	AuditTransferLimitUpsert           = "transfer_limit.upsert"
+	AuditAdjustmentCreate              = "adjustment.create"
+	AuditSuspenseAccountUpsert         = "suspense_account.upsert"
+	AuditJournalPost                   = "journal.post"
+	AuditAccountProductUpsert          = "account_product.upsert"
+	AuditSavingsAccountCreate          = "savings_account.create"
+	AuditInterestCapitalize            = "interest.capitalize"
+)
+
+// Actors that aren't users
+const (
+	// AuditActorSystem is the actor of changes made outside of an API call, like background jobs
+	AuditActorSystem = "system"
+	// AuditActorAnonymous is the actor of unauthenticated API calls, like signing up
+	AuditActorAnonymous = "anonymous"
+)
+
+// Kinds of audit log breaks
+const (
+	// AuditBreakHash is a row whose content doesn't match its hash
+	AuditBreakHash = "hash_mismatch"
+	// AuditBreakChain is a row that doesn't point to the hash of the row before it
+	AuditBreakChain = "chain_mismatch"
+)
+
+// DefaultAuditChunkSize is how many rows VerifyAuditLog reads per query when none is given
+const DefaultAuditChunkSize = 1000
+
+// AuditActor is who a change is recorded for
+type AuditActor struct {
+	Username  string
+	RequestID string
+	ClientIP  string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a copy of ctx whose changes are recorded for actor
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFrom returns the actor of ctx, changes are made by the system when ctx has none
+func AuditActorFrom(ctx context.Context) AuditActor {
+	if actor, ok := ctx.Value(auditActorKey{}).(AuditActor); ok && actor.Username != "" {
+		return actor
+	}
+	return AuditActor{Username: AuditActorSystem}
+}
+
+// auditRecord is a change waiting to be appended to the audit log
+type auditRecord struct {
+	actor     AuditActor
+	operation string
+	entityID  string
+	before    any
+	after     any
+}
+
+// auditTx is the connection of a transaction run by execTx, it keeps the audit records
+// of the transaction until they are appended at its end
+type auditTx struct {
+	*sql.Tx
+	records []auditRecord
+}
+
+// recordAudit records a change to the entity entityID made by operation in the transaction of q,
+// before is nil for creations and after for deletions
+func recordAudit(ctx context.Context, q *Queries, operation string, entityID any, before any, after any) error {
+	tx, ok := q.db.(*auditTx)
+	if !ok {
+		return fmt.Errorf("cannot record %s outside of a transaction", operation)
+	}
+
+	tx.records = append(tx.records, auditRecord{
+		actor:     AuditActorFrom(ctx),
+		operation: operation,
+		entityID:  fmt.Sprint(entityID),
+		before:    before,
+		after:     after,
+	})
+	return nil
+}
+
+// appendAuditLog writes the records of the transaction at the end of the hash chain.
+// The chain is locked until the transaction ends, so this runs last to hold the lock briefly.
+func (tx *auditTx) appendAuditLog(ctx context.Context) error {
+	if len(tx.records) == 0 {
+		return nil
+	}
+
+	q := New(tx.Tx)
+	if err := q.LockAuditLog(ctx); err != nil {
+		return err
+	}
+
+	prevHash, err := q.GetLastAuditLogHash(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	// postgres keeps microseconds, the hash must be computed on the stored time
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+
+	for _, record := range tx.records {
+		arg := CreateAuditLogParams{
+			Actor:      record.actor.Username,
+			RequestID:  record.actor.RequestID,
+			ClientIp:   record.actor.ClientIP,
+			Operation:  record.operation,
+			EntityType: auditEntityType(record.operation),
+			EntityID:   record.entityID,
+			CreatedAt:  createdAt,
+			PrevHash:   prevHash,
+		}
+
+		if arg.Before, err = json.Marshal(record.before); err != nil {
+			return err
+		}
+
+		if arg.After, err = json.Marshal(record.after); err != nil {
+			return err
+		}
+
+		if arg.Hash, err = auditHash(arg); err != nil {
+			return err
+		}
+
+		if _, err := q.CreateAuditLog(ctx, arg); err != nil {
+			return err
+		}
+		prevHash = arg.Hash
+	}
+
+	tx.records = nil
+	return nil
+}
+
+func auditEntityType(operation string) string {
+	entityType, _, _ := strings.Cut(operation, ".")
+	return entityType
+}
+
+// auditHash is the SHA-256 of the content of an audit log row and of the hash of the row before it
+func auditHash(arg CreateAuditLogParams) (string, error) {
+	data, err := json.Marshal(struct {
+		PrevHash   string          `json:"prev_hash"`
+		Actor      string          `json:"actor"`
+		RequestID  string          `json:"request_id"`
+		ClientIP   string          `json:"client_ip"`
+		Operation  string          `json:"operation"`
+		EntityType string          `json:"entity_type"`
+		EntityID   string          `json:"entity_id"`
+		Before     json.RawMessage `json:"before"`
+		After      json.RawMessage `json:"after"`
+		CreatedAt  time.Time       `json:"created_at"`
+	}{
+		PrevHash:   arg.PrevHash,
+		Actor:      arg.Actor,
+		RequestID:  arg.RequestID,
+		ClientIP:   arg.ClientIp,
+		Operation:  arg.Operation,
+		EntityType: arg.EntityType,
+		EntityID:   arg.EntityID,
+		Before:     arg.Before,
+		After:      arg.After,
+		CreatedAt:  arg.CreatedAt.UTC(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AuditLogBreak is a row of the audit log that was changed, or whose predecessor was
+type AuditLogBreak struct {
+	Kind string `json:"kind"`
+	ID   int64  `json:"id"`
+}
+
+type VerifyAuditLogParams struct {
+	ChunkSize int32 `json:"chunk_size"`
+}
+
+type VerifyAuditLogResult struct {
+	RowsChecked int64           `json:"rows_checked"`
+	Breaks      []AuditLogBreak `json:"breaks"`
+	// LastHash is the hash of the last row checked, keeping it aside detects the removal of later rows
+	LastHash string `json:"last_hash"`
+}
+
+// VerifyAuditLog walks the audit log in id order and reports every row whose hash doesn't match
+// its content, or that doesn't point to the hash of the row before it.
+func (store *SQLStore) VerifyAuditLog(ctx context.Context, arg VerifyAuditLogParams) (VerifyAuditLogResult, error) {
+	res := VerifyAuditLogResult{Breaks: []AuditLogBreak{}}
+
+	chunkSize := arg.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultAuditChunkSize
+	}
+
+	var afterID int64
+	for {
+		rows, err := store.ListAuditLogAfter(ctx, ListAuditLogAfterParams{
+			AfterID:    afterID,
+			LimitCount: chunkSize,
+		})
+		if err != nil {
+			return res, err
+		}
+
+		if len(rows) == 0 {
+			return res, nil
+		}
+
+		for _, row := range rows {
+			if row.PrevHash != res.LastHash {
+				res.Breaks = append(res.Breaks, AuditLogBreak{Kind: AuditBreakChain, ID: row.ID})
+			}
+
+			hash, err := auditHash(CreateAuditLogParams{
+				Actor:      row.Actor,
+				RequestID:  row.RequestID,
+				ClientIp:   row.ClientIp,
+				Operation:  row.Operation,
+				EntityType: row.EntityType,
+				EntityID:   row.EntityID,
+				Before:     row.Before,
+				After:      row.After,
+				CreatedAt:  row.CreatedAt,
+				PrevHash:   row.PrevHash,
+			})
+			if err != nil {
+				return res, err
+			}
+
+			if hash != row.Hash {
+				res.Breaks = append(res.Breaks, AuditLogBreak{Kind: AuditBreakHash, ID: row.ID})
+			}
+
+			res.LastHash = row.Hash
+		}
+
+		res.RowsChecked += int64(len(rows))
+		afterID = rows[len(rows)-1].ID
+	}
+}
@@ -0,0 +1,248 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// The SQLStore methods below shadow the queries of the same name that change data outside of
+// the Tx methods, to run them in a transaction with their audit record.
+// Secrets like password hashes and refresh tokens are left out of the records.
+
+// CreateUser creates a user and records it
+func (store *SQLStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		redacted := user
+		redacted.HashPassword = ""
+		return recordAudit(ctx, q, AuditUserCreate, user.Username, nil, redacted)
+	})
+
+	return user, err
+}
+
+// CreateSession creates a session and records it
+func (store *SQLStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		session, err = q.CreateSession(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditSessionCreate, session.ID, nil, redactSession(session))
+	})
+
+	return session, err
+}
+
+// BlockSession blocks a session and records it
+func (store *SQLStore) BlockSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		session, err = q.BlockSession(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditSessionBlock, session.ID, nil, redactSession(session))
+	})
+
+	return session, err
+}
+
+// BlockUserSessions blocks every session of a user and records it
+func (store *SQLStore) BlockUserSessions(ctx context.Context, username string) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		if err := q.BlockUserSessions(ctx, username); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditUserSessionsBlock, username, nil, nil)
+	})
+}
+
+func redactSession(session Session) Session {
+	session.RefreshToken = ""
+	return session
+}
+
+// CreateScheduledTransfer creates a schedule and records it
+func (store *SQLStore) CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	var schedule ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		schedule, err = q.CreateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditScheduledTransferCreate, schedule.ID, nil, schedule)
+	})
+
+	return schedule, err
+}
+
+// CreateWebhook creates a webhook and records it
+func (store *SQLStore) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	var webhook Webhook
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		webhook, err = q.CreateWebhook(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditWebhookCreate, webhook.ID, nil, redactWebhook(webhook))
+	})
+
+	return webhook, err
+}
+
+// DeleteWebhook deletes a webhook and records what it was
+func (store *SQLStore) DeleteWebhook(ctx context.Context, id int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		webhook, err := q.GetWebhook(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := q.DeleteWebhook(ctx, id); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditWebhookDelete, id, redactWebhook(webhook), nil)
+	})
+}
+
+// ReplayWebhookDeliveries replays deliveries of a webhook and records the replayed ones
+func (store *SQLStore) ReplayWebhookDeliveries(ctx context.Context, arg ReplayWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		deliveries, err = q.ReplayWebhookDeliveries(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditWebhookReplay, arg.WebhookID, nil, deliveries)
+	})
+
+	return deliveries, err
+}
+
+func redactWebhook(webhook Webhook) Webhook {
+	webhook.Secret = ""
+	return webhook
+}
+
+// UpsertFeeSchedule writes a fee rule and records it
+func (store *SQLStore) UpsertFeeSchedule(ctx context.Context, arg UpsertFeeScheduleParams) (FeeSchedule, error) {
+	var schedule FeeSchedule
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		schedule, err = q.UpsertFeeSchedule(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditFeeScheduleUpsert, schedule.ID, nil, schedule)
+	})
+
+	return schedule, err
+}
+
+// UpsertTransferLimit writes transfer limits and records them
+func (store *SQLStore) UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error) {
+	var limit TransferLimit
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		limit, err = q.UpsertTransferLimit(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditTransferLimitUpsert, limit.ID, nil, limit)
+	})
+
+	return limit, err
+}
+
+// UpsertSuspenseAccount sets the suspense account of a currency and records it
+func (store *SQLStore) UpsertSuspenseAccount(ctx context.Context, arg UpsertSuspenseAccountParams) (SuspenseAccount, error) {
+	var suspense SuspenseAccount
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		suspense, err = q.UpsertSuspenseAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditSuspenseAccountUpsert, suspense.Currency, nil, suspense)
+	})
+
+	return suspense, err
+}
+
+// UpsertAccountProduct writes a savings product and records it
+func (store *SQLStore) UpsertAccountProduct(ctx context.Context, arg UpsertAccountProductParams) (AccountProduct, error) {
+	var product AccountProduct
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		product, err = q.UpsertAccountProduct(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditAccountProductUpsert, product.ID, nil, product)
+	})
+
+	return product, err
+}
+
+// CreateSavingsAccount puts an account on a savings product and records it
+func (store *SQLStore) CreateSavingsAccount(ctx context.Context, arg CreateSavingsAccountParams) (SavingsAccount, error) {
+	var savings SavingsAccount
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		savings, err = q.CreateSavingsAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditSavingsAccountCreate, savings.AccountID, nil, savings)
+	})
+
+	return savings, err
+}
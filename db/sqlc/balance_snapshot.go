@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultSnapshotChunkSize is how many accounts SnapshotBalances snapshots per query when none is given
+const DefaultSnapshotChunkSize = 1000
+
+// ErrSnapshotNotSettled is returned by SnapshotBalances while entries dated before the snapshot
+// may still be committed
+var ErrSnapshotNotSettled = errors.New("balance snapshot not settled")
+
+type SnapshotBalancesParams struct {
+	TakenAt   time.Time `json:"taken_at"`
+	ChunkSize int32     `json:"chunk_size"`
+}
+
+type SnapshotBalancesResult struct {
+	TakenAt             time.Time `json:"taken_at"`
+	AccountsSnapshotted int64     `json:"accounts_snapshotted"`
+}
+
+// SnapshotBalances records the balance of every account as of arg.TakenAt, summed from its entries
+// since its previous snapshot. Taking a snapshot again replaces it.
+// It fails with ErrSnapshotNotSettled while a transaction started before arg.TakenAt is open,
+// the entries it writes are dated before the snapshot and would be missed.
+// Times are compared in UTC, like the entries are dated.
+func (store *SQLStore) SnapshotBalances(ctx context.Context, arg SnapshotBalancesParams) (SnapshotBalancesResult, error) {
+	arg.TakenAt = arg.TakenAt.UTC()
+	res := SnapshotBalancesResult{TakenAt: arg.TakenAt}
+
+	cutoff, err := store.GetSnapshotCutoff(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	if arg.TakenAt.After(cutoff) {
+		return res, fmt.Errorf("%w: a transaction open since %s may still write entries before %s",
+			ErrSnapshotNotSettled, cutoff.Format(time.RFC3339), arg.TakenAt.Format(time.RFC3339))
+	}
+
+	chunkSize := arg.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultSnapshotChunkSize
+	}
+
+	var afterID int64
+	for {
+		ids, err := store.CreateBalanceSnapshots(ctx, CreateBalanceSnapshotsParams{
+			TakenAt:    arg.TakenAt,
+			AfterID:    afterID,
+			LimitCount: chunkSize,
+		})
+		if err != nil {
+			return res, err
+		}
+
+		if len(ids) == 0 {
+			return res, nil
+		}
+
+		res.AccountsSnapshotted += int64(len(ids))
+		for _, id := range ids {
+			if id > afterID {
+				afterID = id
+			}
+		}
+	}
+}
+
+type RebuildBalanceSnapshotsParams struct {
+	// From is the time of the first snapshot rebuilt, zero rebuilds them all
+	From      time.Time `json:"from"`
+	ChunkSize int32     `json:"chunk_size"`
+}
+
+type RebuildBalanceSnapshotsResult struct {
+	Snapshots           int64 `json:"snapshots"`
+	AccountsSnapshotted int64 `json:"accounts_snapshotted"`
+}
+
+// RebuildBalanceSnapshots takes again every snapshot taken at or after arg.From, oldest first,
+// so each one starts from a rebuilt snapshot or one taken before arg.From.
+// Accounts missing from a snapshot are added to it.
+func (store *SQLStore) RebuildBalanceSnapshots(ctx context.Context, arg RebuildBalanceSnapshotsParams) (RebuildBalanceSnapshotsResult, error) {
+	var res RebuildBalanceSnapshotsResult
+
+	times, err := store.ListBalanceSnapshotTimes(ctx, arg.From)
+	if err != nil {
+		return res, err
+	}
+
+	for _, takenAt := range times {
+		snapshot, err := store.SnapshotBalances(ctx, SnapshotBalancesParams{
+			TakenAt:   takenAt,
+			ChunkSize: arg.ChunkSize,
+		})
+		if err != nil {
+			return res, err
+		}
+
+		res.Snapshots++
+		res.AccountsSnapshotted += snapshot.AccountsSnapshotted
+	}
+
+	return res, nil
+}
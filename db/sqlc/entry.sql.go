@@ -7,36 +7,62 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id, 
-  amount
+  amount,
+  transfer_id,
+  adjustment_id,
+  journal_id,
+  created_at
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  COALESCE($6::timestamp, now())
+) RETURNING id, account_id, amount, created_at, transfer_id, adjustment_id, journal_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID    int64         `json:"account_id"`
+	Amount       int64         `json:"amount"`
+	TransferID   sql.NullInt64 `json:"transfer_id"`
+	AdjustmentID sql.NullInt64 `json:"adjustment_id"`
+	JournalID    sql.NullInt64 `json:"journal_id"`
+	CreatedAt    sql.NullTime  `json:"created_at"`
 }
 
+// Entries are dated now, unless created_at dates them back.
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.AdjustmentID,
+		arg.JournalID,
+		arg.CreatedAt,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.AdjustmentID,
+		&i.JournalID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id, journal_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,12 +74,41 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.AdjustmentID,
+		&i.JournalID,
 	)
 	return i, err
 }
 
+const getStatementBalances = `-- name: GetStatementBalances :one
+SELECT
+  COALESCE(SUM(amount) FILTER (WHERE created_at < $1), 0)::bigint AS opening_balance,
+  COALESCE(SUM(amount), 0)::bigint AS closing_balance
+FROM entries
+WHERE account_id = $2 AND created_at < $3
+`
+
+type GetStatementBalancesParams struct {
+	FromTime  time.Time `json:"from_time"`
+	AccountID int64     `json:"account_id"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+type GetStatementBalancesRow struct {
+	OpeningBalance int64 `json:"opening_balance"`
+	ClosingBalance int64 `json:"closing_balance"`
+}
+
+func (q *Queries) GetStatementBalances(ctx context.Context, arg GetStatementBalancesParams) (GetStatementBalancesRow, error) {
+	row := q.db.QueryRowContext(ctx, getStatementBalances, arg.FromTime, arg.AccountID, arg.ToTime)
+	var i GetStatementBalancesRow
+	err := row.Scan(&i.OpeningBalance, &i.ClosingBalance)
+	return i, err
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id, journal_id FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -80,6 +135,165 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.AdjustmentID,
+			&i.JournalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJournalEntries = `-- name: ListJournalEntries :many
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id, journal_id FROM entries
+WHERE journal_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listJournalEntries, journalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.AdjustmentID,
+			&i.JournalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+WITH opening AS (
+  SELECT COALESCE(SUM(amount), 0)::bigint AS balance
+  FROM entries
+  WHERE account_id = $1 AND created_at < $2
+)
+SELECT
+  e.id,
+  e.amount,
+  e.transfer_id,
+  e.created_at,
+  CASE
+    WHEN ad.id IS NOT NULL THEN
+      CASE WHEN e.account_id = ad.account_id THEN ad.suspense_account_id ELSE ad.account_id END
+    WHEN t.id IS NULL THEN NULL
+    WHEN e.account_id NOT IN (t.from_account_id, t.to_account_id, COALESCE(t.fee_account_id, 0)) THEN
+      CASE WHEN e.amount < 0 THEN t.to_account_id ELSE t.from_account_id END
+    WHEN (SELECT COUNT(*) FROM entries e2 WHERE e2.transfer_id = t.id AND e2.id < e.id) >= 2 THEN
+      CASE WHEN e.amount < 0 THEN t.fee_account_id ELSE t.from_account_id END
+    WHEN e.amount < 0 THEN t.to_account_id
+    ELSE t.from_account_id
+  END AS counterparty_account_id,
+  (opening.balance + SUM(e.amount) OVER (ORDER BY e.created_at, e.id))::bigint AS running_balance
+FROM entries e
+CROSS JOIN opening
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN adjustments ad ON ad.id = e.adjustment_id
+WHERE e.account_id = $1
+  AND e.created_at >= $2
+  AND e.created_at < $3
+ORDER BY e.created_at, e.id
+`
+
+type ListStatementEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+type ListStatementEntriesRow struct {
+	ID                    int64         `json:"id"`
+	Amount                int64         `json:"amount"`
+	TransferID            sql.NullInt64 `json:"transfer_id"`
+	CreatedAt             time.Time     `json:"created_at"`
+	CounterpartyAccountID sql.NullInt64 `json:"counterparty_account_id"`
+	RunningBalance        int64         `json:"running_balance"`
+}
+
+// A transfer posts its amount pair first and then its fee pair, the fee entries
+// have the house fee account as counterparty. The asset house accounts the legs of
+// a cross-currency transfer go through have the customer on the other side as counterparty.
+// An adjustment is balanced by the suspense account, each is the counterparty of the other.
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStatementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStatementEntriesRow{}
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.TransferID,
+			&i.CreatedAt,
+			&i.CounterpartyAccountID,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferEntries = `-- name: ListTransferEntries :many
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id, journal_id FROM entries
+WHERE transfer_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListTransferEntries(ctx context.Context, transferID sql.NullInt64) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferEntries, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.AdjustmentID,
+			&i.JournalID,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// AccountTierStandard is the tier of accounts that weren't assigned another one
+const AccountTierStandard = "standard"
+
+// feeBpsScale is the number of basis points in a whole
+const feeBpsScale = 10_000
+
+// ErrFeeAccountMismatch is returned when the house fee account of a schedule can't receive the fee
+var ErrFeeAccountMismatch = errors.New("fee account doesn't match the fee currency")
+
+// ComputeFee applies a fee schedule to amount: the flat fee plus the percentage,
+// raised to the minimum and capped at the maximum when it is set.
+func ComputeFee(schedule FeeSchedule, amount int64) int64 {
+	fee := schedule.FlatFee + proportion(amount, int64(schedule.PercentageBps), feeBpsScale)
+
+	if fee < schedule.MinFee {
+		fee = schedule.MinFee
+	}
+
+	if schedule.MaxFee > 0 && fee > schedule.MaxFee {
+		fee = schedule.MaxFee
+	}
+	return fee
+}
+
+// transferFee is the fee a transfer owes and the house account it is paid to
+type transferFee struct {
+	Amount    int64
+	AccountID int64
+}
+
+// planFee picks the fee schedule for the source account's currency and tier, preferring one
+// made for the tier over the one for every tier. Reversals and accounts without a schedule pay nothing.
+func planFee(ctx context.Context, q *Queries, arg TransferTxParams) (transferFee, error) {
+	var fee transferFee
+
+	if arg.ReversalOf.Valid {
+		return fee, nil
+	}
+
+	fromAcc, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return fee, err
+	}
+
+	schedule, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{
+		Currency: fromAcc.Currency,
+		Tier:     fromAcc.Tier,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fee, nil
+		}
+		return fee, err
+	}
+
+	// the house account doesn't pay fees to itself
+	if schedule.FeeAccountID == fromAcc.ID {
+		return fee, nil
+	}
+
+	fee.Amount = ComputeFee(schedule, arg.Amount)
+	if fee.Amount > 0 {
+		fee.AccountID = schedule.FeeAccountID
+	}
+	return fee, nil
+}
+
+// checkFeeAccount makes sure the house account can be credited a fee charged to fromAcc
+func checkFeeAccount(feeAcc Account, fromAcc Account) error {
+	if feeAcc.Currency != fromAcc.Currency {
+		return fmt.Errorf("%w: fee account [%d] is in %s, the fee is in %s",
+			ErrFeeAccountMismatch, feeAcc.ID, feeAcc.Currency, fromAcc.Currency)
+	}
+	return checkAccountActive(feeAcc)
+}
+
+type TransferQuote struct {
+	Amount   int64 `json:"amount"`
+	Fee      int64 `json:"fee"`
+	ToAmount int64 `json:"to_amount"`
+	FxRate   int64 `json:"fx_rate"`
+	// TotalDebit is what the source account pays, the amount plus the fee
+	TotalDebit int64 `json:"total_debit"`
+}
+
+// QuoteTransfer previews the fee and the totals of a transfer without moving any money
+func (store *SQLStore) QuoteTransfer(ctx context.Context, arg TransferTxParams) (TransferQuote, error) {
+	arg = withDestinationLeg(arg)
+
+	fee, err := planFee(ctx, store.Queries, arg)
+	if err != nil {
+		return TransferQuote{}, err
+	}
+
+	return TransferQuote{
+		Amount:     arg.Amount,
+		Fee:        fee.Amount,
+		ToAmount:   arg.ToAmount,
+		FxRate:     arg.FxRate,
+		TotalDebit: arg.Amount + fee.Amount,
+	}, nil
+}
@@ -0,0 +1,253 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Statuses of a hold, only pending holds reserve funds
+const (
+	HoldStatusPending  = "pending"
+	HoldStatusCaptured = "captured"
+	HoldStatusVoided   = "voided"
+	HoldStatusExpired  = "expired"
+)
+
+var (
+	ErrHoldNotPending     = errors.New("hold is not pending")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrCaptureExceedsHold = errors.New("capture exceeds the held amount")
+)
+
+// ErrNoHoldExpired is returned by ExpireHoldTx when no pending hold is past its expiry
+var ErrNoHoldExpired = errors.New("no hold expired")
+
+type AuthorizeTxParams struct {
+	TransferTxParams
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type AuthorizeTxResult struct {
+	Hold        Hold    `json:"hold"`
+	FromAccount Account `json:"from_account"`
+}
+
+// AuthorizeTx reserves the amount of a transfer and its fee on the source account without moving any money.
+// The hold lowers the available balance until it is captured, voided or expires.
+// Authorizing is held to the same transfer limits as the transfer itself.
+func (store *SQLStore) AuthorizeTx(ctx context.Context, arg AuthorizeTxParams) (AuthorizeTxResult, error) {
+	var res AuthorizeTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		transferArg := withDestinationLeg(arg.TransferTxParams)
+
+		fee, err := planFee(ctx, q, transferArg)
+		if err != nil {
+			return err
+		}
+
+		fromAcc, _, err := lockActiveAccounts(ctx, q, transferArg.FromAccountID, transferArg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkTransferLimits(ctx, q, fromAcc, transferArg.Amount, time.Now()); err != nil {
+			return err
+		}
+
+		if err := checkFunds(fromAcc, transferArg.Amount+fee.Amount); err != nil {
+			return err
+		}
+
+		res.FromAccount, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     transferArg.FromAccountID,
+			Amount: transferArg.Amount + fee.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		res.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			FromAccountID: transferArg.FromAccountID,
+			ToAccountID:   transferArg.ToAccountID,
+			Amount:        transferArg.Amount,
+			ToAmount:      transferArg.ToAmount,
+			FxRate:        transferArg.FxRate,
+			Fee:           fee.Amount,
+			ExpiresAt:     arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditHoldAuthorize, res.Hold.ID, nil, res.Hold)
+	})
+
+	return res, err
+}
+
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Amount to capture; zero captures the whole hold. The rest of the hold is released.
+	Amount int64 `json:"amount"`
+}
+
+type CaptureHoldTxResult struct {
+	TransferTxResult
+	Hold Hold `json:"hold"`
+}
+
+// CaptureHoldTx settles a pending hold with a transfer of all or part of its amount and releases the rest
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var res CaptureHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := lockPendingHold(ctx, q, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if !time.Now().Before(hold.ExpiresAt) {
+			return fmt.Errorf("%w: hold [%d] expired at %s", ErrHoldExpired, hold.ID, hold.ExpiresAt)
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+
+		if amount <= 0 || amount > hold.Amount {
+			return fmt.Errorf("%w: hold [%d] is for %d, requested %d", ErrCaptureExceedsHold, hold.ID, hold.Amount, amount)
+		}
+
+		// the recipient gets the same share of the converted amount, at the authorized rate
+		toAmount := hold.ToAmount
+		if amount != hold.Amount {
+			toAmount = proportion(amount, hold.ToAmount, hold.Amount)
+		}
+
+		transferArg := TransferTxParams{
+			FromAccountID: hold.FromAccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+			ToAmount:      toAmount,
+			FxRate:        hold.FxRate,
+		}
+
+		plan, err := planTransfer(ctx, q, transferArg)
+		if err != nil {
+			return err
+		}
+
+		// lock the accounts in id order before releasing the hold, transfer locks the same rows again
+		if _, err := lockAccountsInOrder(ctx, q, plan.accountIDs(transferArg)...); err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:     hold.FromAccountID,
+			Amount: -(hold.Amount + hold.Fee),
+		})
+		if err != nil {
+			return err
+		}
+
+		res.TransferTxResult, err = transfer(ctx, q, transferArg)
+		if err != nil {
+			return err
+		}
+
+		res.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+			ID:             hold.ID,
+			Status:         HoldStatusCaptured,
+			CapturedAmount: amount,
+			TransferID:     sql.NullInt64{Int64: res.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditHoldCapture, hold.ID, hold, res.Hold)
+	})
+
+	return res, err
+}
+
+// VoidHoldTx cancels a pending hold and gives the reserved amount back to the available balance
+func (store *SQLStore) VoidHoldTx(ctx context.Context, holdID int64) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		hold, err = lockPendingHold(ctx, q, holdID)
+		if err != nil {
+			return err
+		}
+
+		hold, err = releaseHold(ctx, q, hold, HoldStatusVoided, AuditHoldVoid)
+		return err
+	})
+
+	return hold, err
+}
+
+// ExpireHoldTx releases the earliest pending hold that expired at now.
+// The hold row is claimed with SKIP LOCKED, so several workers can poll concurrently.
+func (store *SQLStore) ExpireHoldTx(ctx context.Context, now time.Time) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		hold, err = q.GetExpiredHold(ctx, now)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNoHoldExpired
+			}
+			return err
+		}
+
+		hold, err = releaseHold(ctx, q, hold, HoldStatusExpired, AuditHoldExpire)
+		return err
+	})
+
+	return hold, err
+}
+
+func lockPendingHold(ctx context.Context, q *Queries, holdID int64) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return hold, err
+	}
+
+	if hold.Status != HoldStatusPending {
+		return hold, fmt.Errorf("%w: hold [%d] is %s", ErrHoldNotPending, hold.ID, hold.Status)
+	}
+	return hold, nil
+}
+
+// releaseHold gives the held amount and fee back to the source account, closes the hold with status
+// and records it as operation
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string, operation string) (Hold, error) {
+	_, err := q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+		ID:     hold.FromAccountID,
+		Amount: -(hold.Amount + hold.Fee),
+	})
+	if err != nil {
+		return hold, err
+	}
+
+	released, err := q.UpdateHold(ctx, UpdateHoldParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	if err != nil {
+		return hold, err
+	}
+
+	return released, recordAudit(ctx, q, operation, hold.ID, hold, released)
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// ErrIdempotencyKeyReused is returned when a key is replayed with a different request
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+
+type IdempotentTransferTxParams struct {
+	TransferTxParams
+	Username       string `json:"username"`
+	IdempotencyKey string `json:"idempotency_key"`
+	// RequestHash fingerprints the request, so a reused key with another body can be told apart
+	RequestHash string `json:"request_hash"`
+}
+
+type IdempotentTransferTxResult struct {
+	TransferTxResult
+	// Replayed is true when the result was read back from an earlier call with the same key
+	Replayed bool `json:"-"`
+}
+
+// IdempotentTransferTx performs TransferTx at most once per (username, idempotency key).
+// The key and the serialized result are stored in the same transaction as the transfer,
+// so a retry either replays the committed result or runs the transfer from scratch.
+func (store *SQLStore) IdempotentTransferTx(ctx context.Context, arg IdempotentTransferTxParams) (IdempotentTransferTxResult, error) {
+	var res IdempotentTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// a concurrent request holding the same key blocks this insert until it commits or rolls back
+		_, err := q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+			Username:       arg.Username,
+			IdempotencyKey: arg.IdempotencyKey,
+			RequestHash:    arg.RequestHash,
+		})
+
+		if err == sql.ErrNoRows {
+			return replayTransfer(ctx, q, arg, &res)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		res.TransferTxResult, err = transfer(ctx, q, arg.TransferTxParams)
+		if err != nil {
+			return err
+		}
+
+		response, err := json.Marshal(res.TransferTxResult)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.UpdateIdempotencyKeyResponse(ctx, UpdateIdempotencyKeyResponseParams{
+			Username:       arg.Username,
+			IdempotencyKey: arg.IdempotencyKey,
+			Response:       response,
+		})
+		return err
+	})
+
+	return res, err
+}
+
+func replayTransfer(ctx context.Context, q *Queries, arg IdempotentTransferTxParams, res *IdempotentTransferTxResult) error {
+	key, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username:       arg.Username,
+		IdempotencyKey: arg.IdempotencyKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	if key.RequestHash != arg.RequestHash {
+		return ErrIdempotencyKeyReused
+	}
+
+	res.Replayed = true
+	return json.Unmarshal(key.Response, &res.TransferTxResult)
+}
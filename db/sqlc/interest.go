@@ -0,0 +1,280 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"simplebank/util"
+	"time"
+)
+
+// DefaultInterestChunkSize is how many savings accounts AccrueInterest and CapitalizeInterest
+// read per query when none is given
+const DefaultInterestChunkSize = 500
+
+type AccrueInterestParams struct {
+	// Through is the last day accrued, its time of day is ignored
+	Through   time.Time `json:"through"`
+	ChunkSize int32     `json:"chunk_size"`
+}
+
+type AccrueInterestResult struct {
+	AccountsScanned int64 `json:"accounts_scanned"`
+	DaysAccrued     int64 `json:"days_accrued"`
+	// AccrualsCapitalized counts the accruals of the periods capitalized before accruing past their end
+	AccrualsCapitalized int64 `json:"accruals_capitalized"`
+}
+
+// AccrueInterest writes the daily interest of every savings account for each day from the day after
+// its last accrual through arg.Through, so missed days are backfilled. A day earns interest on the
+// balance of its end, summed from the entries, at the rate of the product of the account. The fraction
+// of a minor unit a day earns on top of whole units is carried to the next day.
+// A compounding period is capitalized before the day after it accrues, so a backfill compounds the same
+// as daily runs would have. Running it again for days accrued already changes nothing.
+func (store *SQLStore) AccrueInterest(ctx context.Context, arg AccrueInterestParams) (AccrueInterestResult, error) {
+	var res AccrueInterestResult
+
+	through := accrualDay(arg.Through)
+	err := store.forEachSavingsAccount(ctx, arg.ChunkSize, func(acc ListSavingsAccountsRow) error {
+		res.AccountsScanned++
+
+		carry := acc.InterestRemainder
+		for day := accrualDay(acc.NextAccrualDate); !day.After(through); day = day.AddDate(0, 0, 1) {
+			previous := day.AddDate(0, 0, -1)
+			periodEnd, err := util.PeriodEnd(acc.Compounding, previous)
+			if err != nil {
+				return err
+			}
+
+			// the interest of a period that ended yesterday earns interest from today on
+			if periodEnd.Equal(previous) {
+				capitalized, err := store.capitalizeAccount(ctx, acc.AccountID, periodEnd)
+				if err != nil {
+					return err
+				}
+				res.AccrualsCapitalized += capitalized
+			}
+
+			balances, err := store.GetStatementBalances(ctx, GetStatementBalancesParams{
+				FromTime:  day,
+				AccountID: acc.AccountID,
+				ToTime:    day.AddDate(0, 0, 1),
+			})
+			if err != nil {
+				return err
+			}
+
+			amount, remainder, err := util.DailyInterest(balances.ClosingBalance, acc.RateBps, acc.DayCount, day, carry)
+			if err != nil {
+				return err
+			}
+
+			accrued, err := store.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+				AccountID:   acc.AccountID,
+				AccrualDate: day,
+				Balance:     balances.ClosingBalance,
+				RateBps:     acc.RateBps,
+				DayCount:    acc.DayCount,
+				Amount:      amount,
+				Remainder:   remainder,
+			})
+			if err != nil {
+				return err
+			}
+			res.DaysAccrued += accrued
+			carry = remainder
+		}
+
+		return nil
+	})
+
+	return res, err
+}
+
+type CapitalizeInterestParams struct {
+	// Through is the last day of the compounding periods capitalized, the periods of each account end
+	// on or before it
+	Through   time.Time `json:"through"`
+	ChunkSize int32     `json:"chunk_size"`
+}
+
+type CapitalizeInterestResult struct {
+	AccountsScanned     int64 `json:"accounts_scanned"`
+	AccountsCapitalized int64 `json:"accounts_capitalized"`
+	AccrualsCapitalized int64 `json:"accruals_capitalized"`
+}
+
+// InterestCapitalization is the audit record of the interest capitalized on an account
+type InterestCapitalization struct {
+	AccountID int64     `json:"account_id"`
+	Through   time.Time `json:"through"`
+	Accruals  int64     `json:"accruals"`
+	Amount    int64     `json:"amount"`
+	// JournalID is zero when the accruals earned no interest
+	JournalID int64 `json:"journal_id,omitempty"`
+}
+
+// CapitalizeInterest credits every savings account with the interest accrued in its compounding
+// periods ended by arg.Through, from the interest expense house account of its currency.
+// Periods are capitalized one at a time, each in its own transaction, and periods not fully accrued
+// yet are left for the next run. Capitalized accruals are never posted again.
+func (store *SQLStore) CapitalizeInterest(ctx context.Context, arg CapitalizeInterestParams) (CapitalizeInterestResult, error) {
+	var res CapitalizeInterestResult
+
+	through := accrualDay(arg.Through)
+	err := store.forEachSavingsAccount(ctx, arg.ChunkSize, func(acc ListSavingsAccountsRow) error {
+		res.AccountsScanned++
+
+		lastPeriodEnd, err := util.LastPeriodEnd(acc.Compounding, through)
+		if err != nil {
+			return err
+		}
+
+		var capitalized int64
+		for day := accrualDay(acc.UncapitalizedFrom); ; {
+			periodEnd, err := util.PeriodEnd(acc.Compounding, day)
+			if err != nil {
+				return err
+			}
+
+			// the period must have ended by through and be fully accrued
+			if periodEnd.After(lastPeriodEnd) || !accrualDay(acc.NextAccrualDate).After(periodEnd) {
+				break
+			}
+
+			accruals, err := store.capitalizeAccount(ctx, acc.AccountID, periodEnd)
+			if err != nil {
+				return err
+			}
+			capitalized += accruals
+
+			day = periodEnd.AddDate(0, 0, 1)
+		}
+
+		if capitalized > 0 {
+			res.AccountsCapitalized++
+			res.AccrualsCapitalized += capitalized
+		}
+		return nil
+	})
+
+	return res, err
+}
+
+// capitalizeAccount posts the uncapitalized interest of an account accrued through periodEnd and
+// returns how many accruals it capitalized. The entries are dated at the very end of periodEnd,
+// so the interest shows in the statement of the period and earns interest from the next day.
+func (store *SQLStore) capitalizeAccount(ctx context.Context, accountID int64, periodEnd time.Time) (int64, error) {
+	var capitalized int64
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		acc, err := q.GetAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		expense, err := houseAccount(ctx, q, acc.Currency, AccountKindExpense)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := lockAccountsInOrder(ctx, q, acc.ID, expense.ID)
+		if err != nil {
+			return err
+		}
+
+		accruals, err := q.ListUncapitalizedAccruals(ctx, ListUncapitalizedAccrualsParams{
+			AccountID: acc.ID,
+			Through:   periodEnd,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(accruals) == 0 {
+			return nil
+		}
+
+		record := InterestCapitalization{
+			AccountID: acc.ID,
+			Through:   periodEnd,
+		}
+		for _, accrual := range accruals {
+			record.Amount += accrual.Amount
+		}
+
+		if record.Amount > 0 {
+			journal, err := q.CreateJournal(ctx)
+			if err != nil {
+				return err
+			}
+			record.JournalID = journal.ID
+
+			postings := []Posting{
+				{AccountID: acc.ID, Amount: record.Amount},
+				{AccountID: expense.ID, Amount: -record.Amount},
+			}
+			_, err = postEntries(ctx, q, accounts, postings, CreateEntryParams{
+				JournalID: sql.NullInt64{Int64: journal.ID, Valid: true},
+				CreatedAt: sql.NullTime{Time: periodEnd.AddDate(0, 0, 1).Add(-time.Microsecond), Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+
+			err = publishBalanceChanges(ctx, q, accounts, postingDeltas(postings), AccountBalanceChangedEvent{JournalID: journal.ID})
+			if err != nil {
+				return err
+			}
+		}
+
+		record.Accruals, err = q.CapitalizeInterestAccruals(ctx, CapitalizeInterestAccrualsParams{
+			JournalID: sql.NullInt64{Int64: record.JournalID, Valid: record.JournalID != 0},
+			AccountID: acc.ID,
+			Through:   periodEnd,
+		})
+		if err != nil {
+			return err
+		}
+		capitalized = record.Accruals
+
+		return recordAudit(ctx, q, AuditInterestCapitalize, acc.ID, nil, record)
+	})
+
+	return capitalized, err
+}
+
+// forEachSavingsAccount calls fn with the savings accounts that aren't closed, chunkSize at a time
+func (store *SQLStore) forEachSavingsAccount(ctx context.Context, chunkSize int32, fn func(acc ListSavingsAccountsRow) error) error {
+	if chunkSize <= 0 {
+		chunkSize = DefaultInterestChunkSize
+	}
+
+	var afterID int64
+	for {
+		accounts, err := store.ListSavingsAccounts(ctx, ListSavingsAccountsParams{
+			AfterID:    afterID,
+			LimitCount: chunkSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(accounts) == 0 {
+			return nil
+		}
+
+		for _, acc := range accounts {
+			if err := fn(acc); err != nil {
+				return err
+			}
+		}
+
+		afterID = accounts[len(accounts)-1].AccountID
+	}
+}
+
+// accrualDay returns the UTC day of t, interest accrues per UTC calendar day
+func accrualDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
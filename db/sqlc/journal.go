@@ -0,0 +1,270 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Kinds of accounts. Customer accounts belong to users, the others are house accounts
+// of the bank, seeded once per currency by SeedHouseAccountsTx.
+// Balances are credit positive, so asset and expense accounts usually carry negative ones.
+const (
+	AccountKindCustomer  = "customer"
+	AccountKindAsset     = "asset"
+	AccountKindLiability = "liability"
+	AccountKindRevenue   = "revenue"
+	AccountKindExpense   = "expense"
+	AccountKindSuspense  = "suspense"
+)
+
+// HouseAccountKinds are the kinds of the house accounts every currency has
+var HouseAccountKinds = []string{
+	AccountKindAsset,
+	AccountKindLiability,
+	AccountKindRevenue,
+	AccountKindExpense,
+	AccountKindSuspense,
+}
+
+// HouseOwner is the user the house accounts belong to, it is created by the migrations and can't log in
+const HouseOwner = "house"
+
+// ErrInvalidJournal is returned for a journal with fewer than two postings or a zero posting
+var ErrInvalidJournal = errors.New("invalid journal")
+
+// ErrUnbalancedJournal is returned when the postings of a journal don't sum to zero in every currency
+var ErrUnbalancedJournal = errors.New("journal doesn't balance")
+
+// ErrNoHouseAccount is returned when a house account a posting needs isn't seeded
+var ErrNoHouseAccount = errors.New("no house account")
+
+// Posting moves Amount in or out of an account, credits are positive and debits negative
+type Posting struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+type PostJournalResult struct {
+	Journal Journal `json:"journal"`
+	// Entries are in the order of the postings
+	Entries  []Entry   `json:"entries"`
+	Accounts []Account `json:"accounts"`
+}
+
+// PostJournal writes a balanced set of postings as one journal. The postings must sum to zero
+// in every currency, their accounts must be active, and debited customer accounts must have the funds.
+// House accounts may go negative.
+func (store *SQLStore) PostJournal(ctx context.Context, postings []Posting) (PostJournalResult, error) {
+	var res PostJournalResult
+
+	if len(postings) < 2 {
+		return res, fmt.Errorf("%w: %d postings", ErrInvalidJournal, len(postings))
+	}
+
+	ids := make([]int64, 0, len(postings))
+	for _, posting := range postings {
+		if posting.Amount == 0 {
+			return res, fmt.Errorf("%w: zero posting to account [%d]", ErrInvalidJournal, posting.AccountID)
+		}
+		ids = append(ids, posting.AccountID)
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		accounts, err := lockAccountsInOrder(ctx, q, ids...)
+		if err != nil {
+			return err
+		}
+
+		deltas := postingDeltas(postings)
+		for _, id := range sortedAccountIDs(deltas) {
+			acc := accounts[id]
+			if err := checkAccountActive(acc); err != nil {
+				return err
+			}
+
+			if acc.Kind == AccountKindCustomer && deltas[id] < 0 {
+				if err := checkFunds(acc, -deltas[id]); err != nil {
+					return err
+				}
+			}
+		}
+
+		res.Journal, err = q.CreateJournal(ctx)
+		if err != nil {
+			return err
+		}
+
+		res.Entries, err = postEntries(ctx, q, accounts, postings, CreateEntryParams{
+			JournalID: sql.NullInt64{Int64: res.Journal.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range sortedAccountIDs(deltas) {
+			res.Accounts = append(res.Accounts, accounts[id])
+		}
+
+		err = publishBalanceChanges(ctx, q, accounts, deltas, AccountBalanceChangedEvent{JournalID: res.Journal.ID})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditJournalPost, res.Journal.ID, nil, postings)
+	})
+
+	return res, err
+}
+
+// postEntries writes an entry for each posting and moves the balances of their accounts, after checking
+// that the postings balance in every currency. It is the single way entries are written, for transfers
+// and adjustments too. The accounts must be locked by the caller, they are updated in place.
+// link carries what the entries belong to, like their transfer, and the date of back-dated entries.
+// Back-dated entries are added to the balance snapshots taken after them.
+func postEntries(ctx context.Context, q *Queries, accounts map[int64]Account, postings []Posting, link CreateEntryParams) ([]Entry, error) {
+	totals := make(map[string]int64)
+	for _, posting := range postings {
+		acc, ok := accounts[posting.AccountID]
+		if !ok {
+			return nil, fmt.Errorf("account [%d] of a posting isn't locked", posting.AccountID)
+		}
+		totals[acc.Currency] += posting.Amount
+	}
+
+	for currency, total := range totals {
+		if total != 0 {
+			return nil, fmt.Errorf("%w: postings in %s sum to %d", ErrUnbalancedJournal, currency, total)
+		}
+	}
+
+	entries := make([]Entry, 0, len(postings))
+	for _, posting := range postings {
+		arg := link
+		arg.AccountID = posting.AccountID
+		arg.Amount = posting.Amount
+
+		entry, err := q.CreateEntry(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	// important! always update accounts in the same order to avoid deadlocking concurrent transactions
+	deltas := postingDeltas(postings)
+	for _, id := range sortedAccountIDs(deltas) {
+		acc, err := q.UpdateAccountBalance(ctx, UpdateAccountBalanceParams{
+			ID:     id,
+			Amount: deltas[id],
+		})
+		if err != nil {
+			return nil, err
+		}
+		accounts[id] = acc
+
+		if link.CreatedAt.Valid {
+			err := q.ShiftBalanceSnapshots(ctx, ShiftBalanceSnapshotsParams{
+				Amount:    deltas[id],
+				AccountID: id,
+				DatedAt:   link.CreatedAt.Time,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// postingDeltas sums the postings of each account
+func postingDeltas(postings []Posting) map[int64]int64 {
+	deltas := make(map[int64]int64, len(postings))
+	for _, posting := range postings {
+		deltas[posting.AccountID] += posting.Amount
+	}
+	return deltas
+}
+
+// houseAccount returns the house account of kind in currency
+func houseAccount(ctx context.Context, q *Queries, currency string, kind string) (Account, error) {
+	acc, err := q.GetHouseAccount(ctx, GetHouseAccountParams{
+		Currency: currency,
+		Kind:     kind,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return acc, fmt.Errorf("%w: %s %s", ErrNoHouseAccount, kind, currency)
+	}
+	return acc, err
+}
+
+// SeedHouseAccountsTx creates the house accounts of every kind that are missing in currencies.
+// The suspense account of a currency becomes its adjustment suspense account, unless one is already set.
+// It returns the accounts it created.
+func (store *SQLStore) SeedHouseAccountsTx(ctx context.Context, currencies []string) ([]Account, error) {
+	var created []Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		created = nil
+
+		for _, currency := range currencies {
+			for _, kind := range HouseAccountKinds {
+				_, err := q.GetHouseAccount(ctx, GetHouseAccountParams{
+					Currency: currency,
+					Kind:     kind,
+				})
+				if err == nil {
+					continue
+				}
+
+				if !errors.Is(err, sql.ErrNoRows) {
+					return err
+				}
+
+				acc, err := q.CreateHouseAccount(ctx, CreateHouseAccountParams{
+					Owner:    HouseOwner,
+					Currency: currency,
+					Kind:     kind,
+				})
+				if err != nil {
+					return err
+				}
+				created = append(created, acc)
+
+				if err := recordAudit(ctx, q, AuditAccountCreate, acc.ID, nil, acc); err != nil {
+					return err
+				}
+
+				if kind == AccountKindSuspense {
+					if err := seedSuspenseAccount(ctx, q, acc); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return nil
+	})
+
+	return created, err
+}
+
+// seedSuspenseAccount makes acc the suspense account of its currency if there is none
+func seedSuspenseAccount(ctx context.Context, q *Queries, acc Account) error {
+	_, err := q.GetSuspenseAccount(ctx, acc.Currency)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	suspense, err := q.UpsertSuspenseAccount(ctx, UpsertSuspenseAccountParams{
+		Currency:  acc.Currency,
+		AccountID: acc.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return recordAudit(ctx, q, AuditSuspenseAccountUpsert, suspense.Currency, nil, suspense)
+}
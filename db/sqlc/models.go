@@ -5,28 +5,270 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
+	ID               int64     `json:"id"`
+	Owner            string    `json:"owner"`
+	Balance          int64     `json:"balance"`
+	Currency         string    `json:"currency"`
+	CreatedAt        time.Time `json:"created_at"`
+	OverdraftLimit   int64     `json:"overdraft_limit"`
+	Status           string    `json:"status"`
+	HeldAmount       int64     `json:"held_amount"`
+	AvailableBalance int64     `json:"available_balance"`
+	Tier             string    `json:"tier"`
+	// customer accounts belong to users, the others are house accounts, one per kind and currency
+	Kind string `json:"kind"`
+}
+
+type AccountProduct struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	// annual interest rate in basis points
+	RateBps     int32     `json:"rate_bps"`
+	DayCount    string    `json:"day_count"`
+	Compounding string    `json:"compounding"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Adjustment struct {
+	ID                int64  `json:"id"`
+	AccountID         int64  `json:"account_id"`
+	SuspenseAccountID int64  `json:"suspense_account_id"`
+	Kind              string `json:"kind"`
+	// signed change to the balance of the account, the suspense account moves by the opposite
+	Amount     int64     `json:"amount"`
+	ReasonCode string    `json:"reason_code"`
+	Note       string    `json:"note"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID int64 `json:"id"`
+	// username, anonymous for unauthenticated calls or system for background jobs
+	Actor      string `json:"actor"`
+	RequestID  string `json:"request_id"`
+	ClientIp   string `json:"client_ip"`
+	Operation  string `json:"operation"`
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+	// json rather than jsonb keeps the hashed text as is, null for creations
+	Before json.RawMessage `json:"before"`
+	// null for deletions
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"created_at"`
+	// hash of the previous row, empty for the first one
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+type BalanceSnapshot struct {
+	AccountID int64     `json:"account_id"`
+	TakenAt   time.Time `json:"taken_at"`
+	// sum of the entries of the account created before taken_at
 	Balance   int64     `json:"balance"`
-	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
+	ID           int64         `json:"id"`
+	AccountID    int64         `json:"account_id"`
+	Amount       int64         `json:"amount"`
+	CreatedAt    time.Time     `json:"created_at"`
+	TransferID   sql.NullInt64 `json:"transfer_id"`
+	AdjustmentID sql.NullInt64 `json:"adjustment_id"`
+	JournalID    sql.NullInt64 `json:"journal_id"`
+}
+
+type FeeSchedule struct {
+	ID       int64  `json:"id"`
+	Currency string `json:"currency"`
+	// empty applies to every account tier
+	Tier          string `json:"tier"`
+	FlatFee       int64  `json:"flat_fee"`
+	PercentageBps int32  `json:"percentage_bps"`
+	MinFee        int64  `json:"min_fee"`
+	// zero means no cap
+	MaxFee       int64     `json:"max_fee"`
+	FeeAccountID int64     `json:"fee_account_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Hold struct {
+	ID             int64         `json:"id"`
+	FromAccountID  int64         `json:"from_account_id"`
+	ToAccountID    int64         `json:"to_account_id"`
+	Amount         int64         `json:"amount"`
+	ToAmount       int64         `json:"to_amount"`
+	FxRate         int64         `json:"fx_rate"`
+	Status         string        `json:"status"`
+	CapturedAmount int64         `json:"captured_amount"`
+	TransferID     sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+	Fee            int64         `json:"fee"`
+}
+
+type IdempotencyKey struct {
+	Username       string          `json:"username"`
+	IdempotencyKey string          `json:"idempotency_key"`
+	RequestHash    string          `json:"request_hash"`
+	Response       json.RawMessage `json:"response"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// balance at the end of the accrual date, the interest is earned on
+	Balance  int64  `json:"balance"`
+	RateBps  int32  `json:"rate_bps"`
+	DayCount string `json:"day_count"`
+	Amount   int64  `json:"amount"`
+	// null until capitalized, and for capitalized accruals of no interest
+	JournalID     sql.NullInt64 `json:"journal_id"`
+	CapitalizedAt sql.NullTime  `json:"capitalized_at"`
+	CreatedAt     time.Time     `json:"created_at"`
+	// fraction of a minor unit of interest left over after the day, in 1/262800000, carried to the next day
+	Remainder int64 `json:"remainder"`
+}
+
+type Journal struct {
 	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Outbox struct {
+	ID        int64  `json:"id"`
+	EventType string `json:"event_type"`
+	// users whose webhooks hear about the event
+	Owners       []string        `json:"owners"`
+	Payload      json.RawMessage `json:"payload"`
+	CreatedAt    time.Time       `json:"created_at"`
+	DispatchedAt sql.NullTime    `json:"dispatched_at"`
+}
+
+type SavingsAccount struct {
+	AccountID int64 `json:"account_id"`
+	ProductID int64 `json:"product_id"`
+	// first day the account earns interest
+	AccruesFrom time.Time `json:"accrues_from"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64          `json:"id"`
+	Owner         string         `json:"owner"`
+	FromAccountID int64          `json:"from_account_id"`
+	ToAccountID   int64          `json:"to_account_id"`
+	Amount        int64          `json:"amount"`
+	Recurrence    string         `json:"recurrence"`
+	DayOfMonth    sql.NullInt32  `json:"day_of_month"`
+	ScheduledFor  time.Time      `json:"scheduled_for"`
+	NextRunAt     time.Time      `json:"next_run_at"`
+	Status        string         `json:"status"`
+	Attempts      int32          `json:"attempts"`
+	LastError     sql.NullString `json:"last_error"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64          `json:"id"`
+	ScheduledTransferID int64          `json:"scheduled_transfer_id"`
+	TransferID          sql.NullInt64  `json:"transfer_id"`
+	Attempt             int32          `json:"attempt"`
+	Status              string         `json:"status"`
+	Error               sql.NullString `json:"error"`
+	CreatedAt           time.Time      `json:"created_at"`
+}
+
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsBlocked    bool      `json:"is_blocked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type SuspenseAccount struct {
+	Currency string `json:"currency"`
+	// balancing account of the adjustments in currency
 	AccountID int64     `json:"account_id"`
-	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
 type Transfer struct {
-	ID            int64     `json:"id"`
-	FromAccountID int64     `json:"from_account_id"`
-	ToAccountID   int64     `json:"to_account_id"`
-	Amount        int64     `json:"amount"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            int64         `json:"id"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	CreatedAt     time.Time     `json:"created_at"`
+	ReversalOf    sql.NullInt64 `json:"reversal_of"`
+	ToAmount      int64         `json:"to_amount"`
+	FxRate        int64         `json:"fx_rate"`
+	BatchID       sql.NullInt64 `json:"batch_id"`
+	Fee           int64         `json:"fee"`
+	FeeAccountID  sql.NullInt64 `json:"fee_account_id"`
+}
+
+type TransferBatch struct {
+	ID        int64     `json:"id"`
+	Owner     string    `json:"owner"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// a row without account_id and owner holds the global defaults
+type TransferLimit struct {
+	ID        int64          `json:"id"`
+	AccountID sql.NullInt64  `json:"account_id"`
+	Owner     sql.NullString `json:"owner"`
+	// null leaves the limit unset
+	DailyCount    sql.NullInt64 `json:"daily_count"`
+	DailyAmount   sql.NullInt64 `json:"daily_amount"`
+	MonthlyCount  sql.NullInt64 `json:"monthly_count"`
+	MonthlyAmount sql.NullInt64 `json:"monthly_amount"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type User struct {
+	Username          string    `json:"username"`
+	HashPassword      string    `json:"hash_password"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	CreatedAt         time.Time `json:"created_at"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+}
+
+type Webhook struct {
+	ID     int64  `json:"id"`
+	Owner  string `json:"owner"`
+	Url    string `json:"url"`
+	Secret string `json:"secret"`
+	// empty subscribes to every event type
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID            int64          `json:"id"`
+	WebhookID     int64          `json:"webhook_id"`
+	EventID       int64          `json:"event_id"`
+	Status        string         `json:"status"`
+	Attempts      int32          `json:"attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     sql.NullString `json:"last_error"`
+	DeliveredAt   sql.NullTime   `json:"delivered_at"`
+	CreatedAt     time.Time      `json:"created_at"`
 }
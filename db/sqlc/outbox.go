@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Types of the events written to the outbox
+const (
+	EventAccountCreated        = "account.created"
+	EventTransferCreated       = "transfer.created"
+	EventAccountBalanceChanged = "account.balance_changed"
+)
+
+// DefaultOutboxBatchSize is how many events DispatchOutboxTx fans out per call when none is given
+const DefaultOutboxBatchSize = 100
+
+// AccountBalanceChangedEvent is the payload of an account.balance_changed event,
+// the change comes from a transfer, an adjustment or a journal
+type AccountBalanceChangedEvent struct {
+	AccountID int64 `json:"account_id"`
+	// Balance is the balance after the change
+	Balance      int64 `json:"balance"`
+	Change       int64 `json:"change"`
+	TransferID   int64 `json:"transfer_id,omitempty"`
+	AdjustmentID int64 `json:"adjustment_id,omitempty"`
+	JournalID    int64 `json:"journal_id,omitempty"`
+}
+
+// publishEvent writes an event to the outbox in the transaction of q, so the event is only
+// delivered if the change it describes is committed
+func publishEvent(ctx context.Context, q *Queries, eventType string, owners []string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventType: eventType,
+		Owners:    owners,
+		Payload:   data,
+	})
+	return err
+}
+
+// publishTransferEvents writes the transfer.created event, heard by the owners of both accounts,
+// and an account.balance_changed event for every account whose balance moved
+func publishTransferEvents(ctx context.Context, q *Queries, transfer Transfer, accounts map[int64]Account, deltas map[int64]int64) error {
+	owners := []string{accounts[transfer.FromAccountID].Owner}
+	if to := accounts[transfer.ToAccountID].Owner; to != owners[0] {
+		owners = append(owners, to)
+	}
+
+	if err := publishEvent(ctx, q, EventTransferCreated, owners, transfer); err != nil {
+		return err
+	}
+
+	return publishBalanceChanges(ctx, q, accounts, deltas, AccountBalanceChangedEvent{TransferID: transfer.ID})
+}
+
+// publishBalanceChanges writes an account.balance_changed event for every account whose balance moved,
+// source tells what moved it
+func publishBalanceChanges(ctx context.Context, q *Queries, accounts map[int64]Account, deltas map[int64]int64, source AccountBalanceChangedEvent) error {
+	for _, id := range sortedAccountIDs(deltas) {
+		if deltas[id] == 0 {
+			continue
+		}
+
+		event := source
+		event.AccountID = id
+		event.Balance = accounts[id].Balance
+		event.Change = deltas[id]
+
+		if err := publishEvent(ctx, q, EventAccountBalanceChanged, []string{accounts[id].Owner}, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateAccountTx creates an account, its account.created event and its audit record
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var acc Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		acc, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		if err := publishEvent(ctx, q, EventAccountCreated, []string{acc.Owner}, acc); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditAccountCreate, acc.ID, nil, acc)
+	})
+
+	return acc, err
+}
+
+// DispatchOutboxTx fans the oldest undispatched events out to a delivery per subscribed webhook
+// and returns how many events it dispatched. Events are claimed with SKIP LOCKED, so several
+// dispatchers can run concurrently.
+func (store *SQLStore) DispatchOutboxTx(ctx context.Context, limit int32) (int, error) {
+	if limit <= 0 {
+		limit = DefaultOutboxBatchSize
+	}
+
+	var count int
+	err := store.execTx(ctx, func(q *Queries) error {
+		events, err := q.ListPendingOutboxEvents(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			webhooks, err := q.ListEventWebhooks(ctx, ListEventWebhooksParams{
+				Owners:    event.Owners,
+				EventType: event.EventType,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, webhook := range webhooks {
+				err := q.CreateWebhookDelivery(ctx, CreateWebhookDeliveryParams{
+					WebhookID: webhook.ID,
+					EventID:   event.ID,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := q.MarkOutboxEventDispatched(ctx, event.ID); err != nil {
+				return err
+			}
+		}
+
+		count = len(events)
+		return nil
+	})
+
+	return count, err
+}
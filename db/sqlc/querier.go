@@ -6,22 +6,134 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error)
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
+	BlockUserSessions(ctx context.Context, username string) error
+	CapitalizeInterestAccruals(ctx context.Context, arg CapitalizeInterestAccrualsParams) (int64, error)
+	// Claiming counts the attempt and leases the delivery until lease_until,
+	// it is retried from then on if the worker never reports back.
+	ClaimDueWebhookDelivery(ctx context.Context, arg ClaimDueWebhookDeliveryParams) (ClaimDueWebhookDeliveryRow, error)
+	CountOpenScheduledTransfers(ctx context.Context, fromAccountID int64) (int64, error)
+	CountPendingHolds(ctx context.Context, fromAccountID int64) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (Adjustment, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
+	// Snapshots the next limit_count accounts after after_id that existed at taken_at. Each balance
+	// starts from the snapshot before, so snapshots must be taken in order of time.
+	CreateBalanceSnapshots(ctx context.Context, arg CreateBalanceSnapshotsParams) ([]int64, error)
+	// Entries are dated now, unless created_at dates them back.
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateHouseAccount(ctx context.Context, arg CreateHouseAccountParams) (Account, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	// An account accrues once per day, a day accrued already is left alone.
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error)
+	CreateJournal(ctx context.Context) (Journal, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreateSavingsAccount(ctx context.Context, arg CreateSavingsAccountParams) (SavingsAccount, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferBatch(ctx context.Context, owner string) (TransferBatch, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteWebhook(ctx context.Context, id int64) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountProduct(ctx context.Context, id int64) (AccountProduct, error)
+	GetAccountTransferTotals(ctx context.Context, arg GetAccountTransferTotalsParams) (GetAccountTransferTotalsRow, error)
+	GetAdjustment(ctx context.Context, id int64) (Adjustment, error)
+	// The balance of the entries created before as_of, summed from the last snapshot taken by then.
+	GetBalanceAt(ctx context.Context, arg GetBalanceAtParams) (GetBalanceAtRow, error)
+	GetDueScheduledTransfer(ctx context.Context, nextRunAt time.Time) (ScheduledTransfer, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetExpiredHold(ctx context.Context, expiresAt time.Time) (Hold, error)
+	GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetHouseAccount(ctx context.Context, arg GetHouseAccountParams) (Account, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLastAuditLogHash(ctx context.Context) (string, error)
+	GetOutboxEvent(ctx context.Context, id int64) (Outbox, error)
+	GetOwnerTransferTotals(ctx context.Context, arg GetOwnerTransferTotalsParams) (GetOwnerTransferTotalsRow, error)
+	GetReversedAmount(ctx context.Context, reversalOf sql.NullInt64) (int64, error)
+	GetSavingsAccount(ctx context.Context, accountID int64) (SavingsAccount, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	// The latest time a snapshot can be taken at: entries are dated when their transaction starts,
+	// so the ones dated before the start of a transaction still open may not be committed yet.
+	GetSnapshotCutoff(ctx context.Context) (time.Time, error)
+	GetStatementBalances(ctx context.Context, arg GetStatementBalancesParams) (GetStatementBalancesRow, error)
+	GetSuspenseAccount(ctx context.Context, currency string) (SuspenseAccount, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetUser(ctx context.Context, username string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	GetWebhook(ctx context.Context, id int64) (Webhook, error)
+	ListAccountEntryTotals(ctx context.Context, arg ListAccountEntryTotalsParams) ([]ListAccountEntryTotalsRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsByID(ctx context.Context, ids []int64) ([]Account, error)
+	ListAdjustments(ctx context.Context, arg ListAdjustmentsParams) ([]Adjustment, error)
+	ListApplicableTransferLimits(ctx context.Context, arg ListApplicableTransferLimitsParams) ([]TransferLimit, error)
+	// Empty filters and null times match every row.
+	ListAuditLog(ctx context.Context, arg ListAuditLogParams) ([]AuditLog, error)
+	ListAuditLogAfter(ctx context.Context, arg ListAuditLogAfterParams) ([]AuditLog, error)
+	ListBalanceSnapshotTimes(ctx context.Context, fromTime time.Time) ([]time.Time, error)
+	ListBatchTransfers(ctx context.Context, batchID sql.NullInt64) ([]Transfer, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEventWebhooks(ctx context.Context, arg ListEventWebhooksParams) ([]Webhook, error)
+	ListFeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	ListJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]Entry, error)
+	ListPendingOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error)
+	// Accounts accrue from the day after their last accrual, closed accounts no longer do.
+	// uncapitalized_from is the first day accrued and not capitalized yet, or next_accrual_date if there is none.
+	ListSavingsAccounts(ctx context.Context, arg ListSavingsAccountsParams) ([]ListSavingsAccountsRow, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	// A transfer posts its amount pair first and then its fee pair, the fee entries
+	// have the house fee account as counterparty. The asset house accounts the legs of
+	// a cross-currency transfer go through have the customer on the other side as counterparty.
+	// An adjustment is balanced by the suspense account, each is the counterparty of the other.
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	ListTransferEntries(ctx context.Context, transferID sql.NullInt64) ([]Entry, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUncapitalizedAccruals(ctx context.Context, arg ListUncapitalizedAccrualsParams) ([]InterestAccrual, error)
+	ListUnmatchedTransfers(ctx context.Context, arg ListUnmatchedTransfersParams) ([]ListUnmatchedTransfersRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhooks(ctx context.Context, owner string) ([]Webhook, error)
+	// Serializes appends to the hash chain until the transaction ends.
+	LockAuditLog(ctx context.Context) error
+	MarkOutboxEventDispatched(ctx context.Context, id int64) error
+	// No ids replays every dead delivery of the webhook. Pending deliveries are left alone.
+	ReplayWebhookDeliveries(ctx context.Context, arg ReplayWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	RescheduleScheduledTransfer(ctx context.Context, arg RescheduleScheduledTransferParams) (ScheduledTransfer, error)
+	// Adds the amount of an entry dated before snapshots already taken to each of them.
+	ShiftBalanceSnapshots(ctx context.Context, arg ShiftBalanceSnapshotsParams) error
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
 	UpdateAccountBalance(ctx context.Context, arg UpdateAccountBalanceParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateAccountTier(ctx context.Context, arg UpdateAccountTierParams) (Account, error)
+	UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) (IdempotencyKey, error)
+	UpdateScheduledTransferStatus(ctx context.Context, arg UpdateScheduledTransferStatusParams) (ScheduledTransfer, error)
+	UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) (WebhookDelivery, error)
+	UpsertAccountProduct(ctx context.Context, arg UpsertAccountProductParams) (AccountProduct, error)
+	UpsertFeeSchedule(ctx context.Context, arg UpsertFeeScheduleParams) (FeeSchedule, error)
+	UpsertSuspenseAccount(ctx context.Context, arg UpsertSuspenseAccountParams) (SuspenseAccount, error)
+	UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error)
 }
 
 var _ Querier = (*Queries)(nil)
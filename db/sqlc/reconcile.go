@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+)
+
+// Kinds of ledger discrepancies
+const (
+	// DiscrepancyBalance is an account whose balance differs from the sum of its entries
+	DiscrepancyBalance = "balance_mismatch"
+	// DiscrepancyDebitEntries is a transfer whose debit entry is missing or duplicated
+	DiscrepancyDebitEntries = "debit_entries_mismatch"
+	// DiscrepancyCreditEntries is a transfer whose credit entry is missing or duplicated
+	DiscrepancyCreditEntries = "credit_entries_mismatch"
+)
+
+// DefaultReconcileChunkSize is how many accounts ReconcileLedger scans per query when none is given
+const DefaultReconcileChunkSize = 500
+
+// LedgerDiscrepancy is a broken ledger invariant. For balances Expected is the sum of the
+// entries and Actual the balance, for transfers they count the matching entries.
+type LedgerDiscrepancy struct {
+	Kind       string `json:"kind"`
+	AccountID  int64  `json:"account_id"`
+	TransferID int64  `json:"transfer_id,omitempty"`
+	Expected   int64  `json:"expected"`
+	Actual     int64  `json:"actual"`
+}
+
+type ReconcileLedgerParams struct {
+	ChunkSize int32 `json:"chunk_size"`
+}
+
+type ReconcileLedgerResult struct {
+	AccountsScanned int64               `json:"accounts_scanned"`
+	Discrepancies   []LedgerDiscrepancy `json:"discrepancies"`
+}
+
+// ReconcileLedger scans the accounts in chunks of ids and reports every account whose balance
+// isn't the sum of its entries, and every transfer sent from them whose entries miss or repeat a leg.
+// The entries of a transfer are the ones carrying its id: the debit of the amount and of the fee
+// from the sender, and the credit of the converted amount to the recipient.
+// Each query reads a consistent snapshot, so concurrent transfers don't show up as discrepancies.
+func (store *SQLStore) ReconcileLedger(ctx context.Context, arg ReconcileLedgerParams) (ReconcileLedgerResult, error) {
+	res := ReconcileLedgerResult{Discrepancies: []LedgerDiscrepancy{}}
+
+	chunkSize := arg.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultReconcileChunkSize
+	}
+
+	var afterID int64
+	for {
+		accounts, err := store.ListAccountEntryTotals(ctx, ListAccountEntryTotalsParams{
+			AfterID:    afterID,
+			LimitCount: chunkSize,
+		})
+		if err != nil {
+			return res, err
+		}
+
+		if len(accounts) == 0 {
+			return res, nil
+		}
+
+		for _, acc := range accounts {
+			if acc.Balance != acc.EntriesTotal {
+				res.Discrepancies = append(res.Discrepancies, LedgerDiscrepancy{
+					Kind:      DiscrepancyBalance,
+					AccountID: acc.ID,
+					Expected:  acc.EntriesTotal,
+					Actual:    acc.Balance,
+				})
+			}
+		}
+
+		transfers, err := store.ListUnmatchedTransfers(ctx, ListUnmatchedTransfersParams{
+			FirstAccountID: accounts[0].ID,
+			LastAccountID:  accounts[len(accounts)-1].ID,
+		})
+		if err != nil {
+			return res, err
+		}
+
+		for _, transfer := range transfers {
+			if transfer.DebitEntries != transfer.ExpectedDebits {
+				res.Discrepancies = append(res.Discrepancies, LedgerDiscrepancy{
+					Kind:       DiscrepancyDebitEntries,
+					AccountID:  transfer.FromAccountID,
+					TransferID: transfer.ID,
+					Expected:   transfer.ExpectedDebits,
+					Actual:     transfer.DebitEntries,
+				})
+			}
+
+			if transfer.CreditEntries != transfer.ExpectedCredits {
+				res.Discrepancies = append(res.Discrepancies, LedgerDiscrepancy{
+					Kind:       DiscrepancyCreditEntries,
+					AccountID:  transfer.ToAccountID,
+					TransferID: transfer.ID,
+					Expected:   transfer.ExpectedCredits,
+					Actual:     transfer.CreditEntries,
+				})
+			}
+		}
+
+		res.AccountsScanned += int64(len(accounts))
+		afterID = accounts[len(accounts)-1].ID
+	}
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"simplebank/util"
+)
+
+// ErrTransferIsReversal is returned when trying to reverse a compensating transfer
+var ErrTransferIsReversal = errors.New("a reversal cannot be reversed")
+
+// ErrRefundExceedsRemaining is matched by every RefundExceedsRemainingError
+var ErrRefundExceedsRemaining = errors.New("refund exceeds the remaining refundable amount")
+
+// RefundExceedsRemainingError is returned when a reversal asks for more than what is left to refund
+type RefundExceedsRemainingError struct {
+	TransferID int64
+	Remaining  int64
+	Requested  int64
+}
+
+func (e *RefundExceedsRemainingError) Error() string {
+	return fmt.Sprintf("transfer [%d] has %d left to refund, requested %d", e.TransferID, e.Remaining, e.Requested)
+}
+
+func (e *RefundExceedsRemainingError) Is(target error) bool {
+	return target == ErrRefundExceedsRemaining
+}
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount to refund in the original sender's currency; zero refunds everything that is still refundable
+	Amount int64 `json:"amount"`
+}
+
+type ReverseTransferTxResult struct {
+	TransferTxResult
+	OriginalTransfer Transfer `json:"original_transfer"`
+	// RemainingAmount is what can still be refunded after this reversal
+	RemainingAmount int64 `json:"remaining_amount"`
+}
+
+// ReverseTransferTx refunds all or part of a transfer with a compensating transfer in the opposite direction.
+// The original transfer row is locked, so concurrent reversals can't refund more than its amount in total.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var res ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		res.OriginalTransfer, err = q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if res.OriginalTransfer.ReversalOf.Valid {
+			return ErrTransferIsReversal
+		}
+
+		reversalOf := sql.NullInt64{Int64: arg.TransferID, Valid: true}
+		reversed, err := q.GetReversedAmount(ctx, reversalOf)
+		if err != nil {
+			return err
+		}
+
+		remaining := res.OriginalTransfer.Amount - reversed
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+
+		if amount <= 0 || amount > remaining {
+			return &RefundExceedsRemainingError{
+				TransferID: arg.TransferID,
+				Remaining:  remaining,
+				Requested:  amount,
+			}
+		}
+
+		// the recipient gives back the same share of what it was credited, at the original rate
+		original := res.OriginalTransfer
+		debit := amount
+		if original.ToAmount != original.Amount {
+			debit = proportion(amount, original.ToAmount, original.Amount)
+		}
+
+		res.TransferTxResult, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        debit,
+			ReversalOf:    reversalOf,
+			ToAmount:      amount,
+			FxRate:        inverseRate(original.FxRate),
+		})
+		if err != nil {
+			return err
+		}
+
+		res.RemainingAmount = remaining - amount
+		return nil
+	})
+
+	return res, err
+}
+
+func inverseRate(rate int64) int64 {
+	if rate == 0 {
+		return util.FXRateScale
+	}
+	return proportion(util.FXRateScale, util.FXRateScale, rate)
+}
+
+// proportion computes value*num/den for non-negative operands, rounding half up.
+// The product is done in big integers so large amounts can't overflow.
+func proportion(value int64, num int64, den int64) int64 {
+	product := new(big.Int).Mul(big.NewInt(value), big.NewInt(num))
+	product.Add(product, big.NewInt(den/2))
+	return product.Quo(product, big.NewInt(den)).Int64()
+}
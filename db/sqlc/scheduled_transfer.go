@@ -0,0 +1,286 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Recurrences of a scheduled transfer
+const (
+	RecurrenceOnce    = "once"
+	RecurrenceDaily   = "daily"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+)
+
+// Statuses of a scheduled transfer, only active ones are picked by the worker
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusPaused    = "paused"
+	ScheduleStatusCancelled = "cancelled"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusFailed    = "failed"
+)
+
+// Outcomes of a scheduled transfer run
+const (
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+)
+
+const (
+	// MaxScheduledTransferAttempts is how many times an occurrence is tried before it is given up
+	MaxScheduledTransferAttempts = 5
+	scheduleRetryBaseDelay       = time.Minute
+	scheduleRetryMaxDelay        = 6 * time.Hour
+)
+
+// ErrNoScheduledTransferDue is returned by ExecuteScheduledTransferTx when nothing is due
+var ErrNoScheduledTransferDue = errors.New("no scheduled transfer due")
+
+// allowedScheduleTransitions maps a schedule status to the ones a user can move it to
+var allowedScheduleTransitions = map[string][]string{
+	ScheduleStatusActive: {ScheduleStatusPaused, ScheduleStatusCancelled},
+	ScheduleStatusPaused: {ScheduleStatusActive, ScheduleStatusCancelled},
+}
+
+type ExecuteScheduledTransferTxResult struct {
+	ScheduledTransfer ScheduledTransfer    `json:"scheduled_transfer"`
+	Run               ScheduledTransferRun `json:"run"`
+}
+
+// ExecuteScheduledTransferTx runs the transfer of the earliest due schedule and records the run.
+// The schedule row is claimed with SKIP LOCKED, so several workers can poll concurrently.
+// Insufficient funds and serialization failures are retried with backoff, any other transfer
+// failure, like a frozen account or a missing house account, gives up the occurrence so a broken
+// schedule can't hold up the queue. Only a lost connection rolls the run back.
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, now time.Time) (ExecuteScheduledTransferTxResult, error) {
+	var res ExecuteScheduledTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		schedule, err := q.GetDueScheduledTransfer(ctx, now)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNoScheduledTransferDue
+			}
+			return err
+		}
+
+		attempt := schedule.Attempts + 1
+		runArg := CreateScheduledTransferRunParams{
+			ScheduledTransferID: schedule.ID,
+			Attempt:             attempt,
+		}
+
+		// the transfer runs in a savepoint, so the run can still be recorded when it fails half way
+		var transferRes TransferTxResult
+		transferErr := withSavepoint(ctx, q, "scheduled_transfer", func() error {
+			var err error
+			transferRes, err = transfer(ctx, q, TransferTxParams{
+				FromAccountID: schedule.FromAccountID,
+				ToAccountID:   schedule.ToAccountID,
+				Amount:        schedule.Amount,
+			})
+			return err
+		})
+
+		var next RescheduleScheduledTransferParams
+		switch {
+		case transferErr == nil:
+			runArg.Status = RunStatusSucceeded
+			runArg.TransferID = sql.NullInt64{Int64: transferRes.Transfer.ID, Valid: true}
+			next = advanceSchedule(schedule, now, ScheduleStatusCompleted)
+		case isConnectionError(transferErr):
+			return transferErr
+		case isRetryableTransferError(transferErr) && attempt < MaxScheduledTransferAttempts:
+			runArg.Status = RunStatusFailed
+			runArg.Error = sql.NullString{String: transferErr.Error(), Valid: true}
+			next = RescheduleScheduledTransferParams{
+				ID:           schedule.ID,
+				Status:       schedule.Status,
+				ScheduledFor: schedule.ScheduledFor,
+				NextRunAt:    now.Add(ScheduleRetryBackoff(attempt)),
+				Attempts:     attempt,
+				LastError:    runArg.Error,
+			}
+		default:
+			runArg.Status = RunStatusFailed
+			runArg.Error = sql.NullString{String: transferErr.Error(), Valid: true}
+			next = advanceSchedule(schedule, now, ScheduleStatusFailed)
+			next.LastError = runArg.Error
+		}
+
+		res.Run, err = q.CreateScheduledTransferRun(ctx, runArg)
+		if err != nil {
+			return err
+		}
+
+		res.ScheduledTransfer, err = q.RescheduleScheduledTransfer(ctx, next)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditScheduledTransferRun, schedule.ID, schedule, res.ScheduledTransfer)
+	})
+
+	return res, err
+}
+
+type ChangeScheduledTransferStatusTxParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+// ChangeScheduledTransferStatusTx pauses, resumes or cancels a schedule.
+// Resuming a recurring schedule skips the occurrences missed while it was paused.
+func (store *SQLStore) ChangeScheduledTransferStatusTx(ctx context.Context, arg ChangeScheduledTransferStatusTxParams) (ScheduledTransfer, error) {
+	var schedule ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		schedule, err = q.GetScheduledTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if !canTransitionSchedule(schedule.Status, arg.Status) {
+			return fmt.Errorf("%w: scheduled transfer [%d] can't go from %s to %s",
+				ErrInvalidStatusTransition, schedule.ID, schedule.Status, arg.Status)
+		}
+
+		before := schedule
+		now := time.Now()
+		if arg.Status == ScheduleStatusActive && schedule.Recurrence != RecurrenceOnce && schedule.NextRunAt.Before(now) {
+			next := advanceSchedule(schedule, now, arg.Status)
+			next.Status = arg.Status
+			schedule, err = q.RescheduleScheduledTransfer(ctx, next)
+		} else {
+			schedule, err = q.UpdateScheduledTransferStatus(ctx, UpdateScheduledTransferStatusParams{
+				ID:     arg.ID,
+				Status: arg.Status,
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, AuditScheduledTransferStatusChange, schedule.ID, before, schedule)
+	})
+
+	return schedule, err
+}
+
+// advanceSchedule moves a schedule to its first occurrence after now and resets its attempts.
+// One-off schedules have no next occurrence and end in doneStatus.
+func advanceSchedule(schedule ScheduledTransfer, now time.Time, doneStatus string) RescheduleScheduledTransferParams {
+	next := RescheduleScheduledTransferParams{
+		ID:           schedule.ID,
+		Status:       schedule.Status,
+		ScheduledFor: schedule.ScheduledFor,
+		NextRunAt:    schedule.NextRunAt,
+	}
+
+	if schedule.Recurrence == RecurrenceOnce {
+		next.Status = doneStatus
+		return next
+	}
+
+	occurrence := schedule.ScheduledFor
+	for !occurrence.After(now) {
+		occurrence = NextOccurrence(schedule.Recurrence, schedule.DayOfMonth.Int32, occurrence)
+	}
+
+	next.ScheduledFor = occurrence
+	next.NextRunAt = occurrence
+	return next
+}
+
+// NextOccurrence returns the occurrence that follows prev. Monthly schedules land on
+// dayOfMonth, or on the last day of shorter months.
+func NextOccurrence(recurrence string, dayOfMonth int32, prev time.Time) time.Time {
+	switch recurrence {
+	case RecurrenceDaily:
+		return prev.AddDate(0, 0, 1)
+	case RecurrenceWeekly:
+		return prev.AddDate(0, 0, 7)
+	case RecurrenceMonthly:
+		return monthlyOccurrence(prev.Year(), prev.Month()+1, dayOfMonth, prev)
+	}
+	return prev
+}
+
+// FirstMonthlyOccurrence returns the first dayOfMonth at or after start, at start's time of day
+func FirstMonthlyOccurrence(start time.Time, dayOfMonth int32) time.Time {
+	occurrence := monthlyOccurrence(start.Year(), start.Month(), dayOfMonth, start)
+	if occurrence.Before(start) {
+		occurrence = monthlyOccurrence(start.Year(), start.Month()+1, dayOfMonth, start)
+	}
+	return occurrence
+}
+
+// monthlyOccurrence builds the date for dayOfMonth in the given month, clamped to its last day
+func monthlyOccurrence(year int, month time.Month, dayOfMonth int32, clock time.Time) time.Time {
+	// day 0 of the following month is the last day of this one
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, clock.Location()).Day()
+
+	day := int(dayOfMonth)
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), clock.Location())
+}
+
+// ScheduleRetryBackoff is the delay before retrying an occurrence that failed attempt times
+func ScheduleRetryBackoff(attempt int32) time.Duration {
+	delay := scheduleRetryBaseDelay
+	for i := int32(1); i < attempt && delay < scheduleRetryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > scheduleRetryMaxDelay {
+		delay = scheduleRetryMaxDelay
+	}
+	return delay
+}
+
+// isRetryableTransferError tells if a failed scheduled transfer may succeed when tried again later
+func isRetryableTransferError(err error) bool {
+	if errors.Is(err, ErrInsufficientFunds) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "serialization_failure", "deadlock_detected", "lock_not_available":
+			return true
+		}
+	}
+	return false
+}
+
+// isConnectionError tells if err left the transaction unusable, so nothing more can be recorded in it
+func isConnectionError(err error) bool {
+	return errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, sql.ErrTxDone) ||
+		errors.Is(err, driver.ErrBadConn)
+}
+
+func canTransitionSchedule(from string, to string) bool {
+	for _, allowed := range allowedScheduleTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type AccountStatementTxParams struct {
+	AccountID int64     `json:"account_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+// AccountStatement lists the entries of an account in [From, To) with the balance after each of them
+type AccountStatement struct {
+	AccountID      int64                     `json:"account_id"`
+	From           time.Time                 `json:"from"`
+	To             time.Time                 `json:"to"`
+	OpeningBalance int64                     `json:"opening_balance"`
+	ClosingBalance int64                     `json:"closing_balance"`
+	Entries        []ListStatementEntriesRow `json:"entries"`
+}
+
+// StatementWriter receives a statement as it is read: the header first, with the balances but
+// no entries, then every entry in booking order
+type StatementWriter interface {
+	WriteHeader(statement AccountStatement) error
+	WriteEntry(entry ListStatementEntriesRow) error
+}
+
+// AccountStatementTx builds the statement of an account between two times. Balances are the sums of
+// the entries, they are read from one snapshot so the entries always add up to the closing balance.
+func (store *SQLStore) AccountStatementTx(ctx context.Context, arg AccountStatementTxParams) (AccountStatement, error) {
+	var statement AccountStatement
+
+	err := store.execTxWithOptions(ctx, statementTxOptions(), func(q *Queries) error {
+		var err error
+		statement, err = statementHeader(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		statement.Entries, err = q.ListStatementEntries(ctx, ListStatementEntriesParams{
+			AccountID: arg.AccountID,
+			FromTime:  arg.From,
+			ToTime:    arg.To,
+		})
+		return err
+	})
+
+	return statement, err
+}
+
+// StreamAccountStatementTx reads the same statement as AccountStatementTx but hands it to w one entry
+// at a time, so statements of any length are written without holding their entries in memory.
+// The snapshot stays open until w has taken the last entry.
+func (store *SQLStore) StreamAccountStatementTx(ctx context.Context, arg AccountStatementTxParams, w StatementWriter) error {
+	return store.execTxWithOptions(ctx, statementTxOptions(), func(q *Queries) error {
+		statement, err := statementHeader(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		if err := w.WriteHeader(statement); err != nil {
+			return err
+		}
+
+		return q.forEachStatementEntry(ctx, ListStatementEntriesParams{
+			AccountID: arg.AccountID,
+			FromTime:  arg.From,
+			ToTime:    arg.To,
+		}, w.WriteEntry)
+	})
+}
+
+func statementTxOptions() *sql.TxOptions {
+	return &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+}
+
+// statementHeader reads the balances of a statement, its entries are left nil
+func statementHeader(ctx context.Context, q *Queries, arg AccountStatementTxParams) (AccountStatement, error) {
+	balances, err := q.GetStatementBalances(ctx, GetStatementBalancesParams{
+		FromTime:  arg.From,
+		AccountID: arg.AccountID,
+		ToTime:    arg.To,
+	})
+	if err != nil {
+		return AccountStatement{}, err
+	}
+
+	return AccountStatement{
+		AccountID:      arg.AccountID,
+		From:           arg.From,
+		To:             arg.To,
+		OpeningBalance: balances.OpeningBalance,
+		ClosingBalance: balances.ClosingBalance,
+	}, nil
+}
+
+// forEachStatementEntry runs the ListStatementEntries query and calls fn on each row as it is scanned
+func (q *Queries) forEachStatementEntry(ctx context.Context, arg ListStatementEntriesParams, fn func(ListStatementEntriesRow) error) error {
+	rows, err := q.db.QueryContext(ctx, listStatementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.TransferID,
+			&i.CreatedAt,
+			&i.CounterpartyAccountID,
+			&i.RunningBalance,
+		); err != nil {
+			return err
+		}
+
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	return rows.Err()
+}
@@ -3,13 +3,41 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"simplebank/util"
+	"sort"
+	"time"
 )
 
 // Store provides all functions to execute queries & transactions
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	IdempotentTransferTx(ctx context.Context, arg IdempotentTransferTxParams) (IdempotentTransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (Account, error)
+	ExecuteScheduledTransferTx(ctx context.Context, now time.Time) (ExecuteScheduledTransferTxResult, error)
+	ChangeScheduledTransferStatusTx(ctx context.Context, arg ChangeScheduledTransferStatusTxParams) (ScheduledTransfer, error)
+	AuthorizeTx(ctx context.Context, arg AuthorizeTxParams) (AuthorizeTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (Hold, error)
+	ExpireHoldTx(ctx context.Context, now time.Time) (Hold, error)
+	TransferBatchTx(ctx context.Context, arg TransferBatchTxParams) (TransferBatchTxResult, error)
+	QuoteTransfer(ctx context.Context, arg TransferTxParams) (TransferQuote, error)
+	ReconcileLedger(ctx context.Context, arg ReconcileLedgerParams) (ReconcileLedgerResult, error)
+	AccountStatementTx(ctx context.Context, arg AccountStatementTxParams) (AccountStatement, error)
+	StreamAccountStatementTx(ctx context.Context, arg AccountStatementTxParams, w StatementWriter) error
+	CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error)
+	DispatchOutboxTx(ctx context.Context, limit int32) (int, error)
+	VerifyAuditLog(ctx context.Context, arg VerifyAuditLogParams) (VerifyAuditLogResult, error)
+	AdjustAccountTx(ctx context.Context, arg AdjustAccountTxParams) (AdjustAccountTxResult, error)
+	PostJournal(ctx context.Context, postings []Posting) (PostJournalResult, error)
+	SeedHouseAccountsTx(ctx context.Context, currencies []string) ([]Account, error)
+	AccrueInterest(ctx context.Context, arg AccrueInterestParams) (AccrueInterestResult, error)
+	CapitalizeInterest(ctx context.Context, arg CapitalizeInterestParams) (CapitalizeInterestResult, error)
+	SnapshotBalances(ctx context.Context, arg SnapshotBalancesParams) (SnapshotBalancesResult, error)
+	RebuildBalanceSnapshots(ctx context.Context, arg RebuildBalanceSnapshotsParams) (RebuildBalanceSnapshotsResult, error)
 }
 
 // Store provides all functions to execute SQL queries & transactions
@@ -28,15 +56,26 @@ func NewStore(db *sql.DB) Store {
 
 // execTx executes a function within db transaction
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+	return store.execTxWithOptions(ctx, nil, fn)
+}
+
+// execTxWithOptions executes a function within a db transaction started with opts.
+// The changes fn records for the audit log are appended before committing.
+func (store *SQLStore) execTxWithOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 
 	if err != nil {
 		return err
 	}
 
-	q := New(tx)
+	atx := &auditTx{Tx: tx}
+	q := New(atx)
 	err = fn(q)
 
+	if err == nil {
+		err = atx.appendAuditLog(ctx)
+	}
+
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("TX err: %v, RB err: %v", err, rbErr)
@@ -47,10 +86,63 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	return tx.Commit()
 }
 
+// withSavepoint runs fn in a savepoint of the transaction of q. If fn fails, what it wrote and the
+// audit records it made are rolled back and the transaction can go on, e.g. to record the failure.
+func withSavepoint(ctx context.Context, q *Queries, name string, fn func() error) error {
+	tx, ok := q.db.(*auditTx)
+	if !ok {
+		return fmt.Errorf("cannot open savepoint %s outside of a transaction", name)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+	records := len(tx.records)
+
+	err := fn()
+	if err == nil {
+		_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+		return err
+	}
+
+	tx.records = tx.records[:records]
+	if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+		return errors.Join(err, rbErr)
+	}
+	return err
+}
+
+// ErrInsufficientFunds is matched by every InsufficientFundsError
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// InsufficientFundsError is returned when a debit would take an account below its overdraft limit
+type InsufficientFundsError struct {
+	AccountID int64
+	// Available is the available balance plus the overdraft limit
+	Available int64
+	Requested int64
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf("account [%d] has insufficient funds: available %d, requested %d", e.AccountID, e.Available, e.Requested)
+}
+
+func (e *InsufficientFundsError) Is(target error) bool {
+	return target == ErrInsufficientFunds
+}
+
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// ReversalOf links a compensating transfer to the one it refunds
+	ReversalOf sql.NullInt64 `json:"reversal_of"`
+	// ToAmount is what the recipient is credited in its own currency, it defaults to Amount
+	ToAmount int64 `json:"to_amount"`
+	// FxRate is the rate applied to Amount scaled by util.FXRateScale, it defaults to 1
+	FxRate int64 `json:"fx_rate"`
+	// BatchID groups the legs of a batch transfer
+	BatchID sql.NullInt64 `json:"batch_id"`
 }
 
 type TransferTxResult struct {
@@ -59,6 +151,8 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Fee is charged to the source account on top of the amount and paid to the house fee account
+	Fee int64 `json:"fee"`
 }
 
 // TransferTx performs money transfer from an acc to anoter.
@@ -68,72 +162,239 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
+		res, err = transfer(ctx, q, arg)
+		return err
+	})
 
-		// create transfer record
-		res.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
+	return res, err
+}
 
-		if err != nil {
-			return err
-		}
+// transfer moves the money using q, so it can be composed into larger transactions
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	var res TransferTxResult
 
-		// create entries
-		res.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+	arg = withDestinationLeg(arg)
 
-		if err != nil {
-			return err
-		}
+	plan, err := planTransfer(ctx, q, arg)
+	if err != nil {
+		return res, err
+	}
+	fee := plan.fee
 
-		res.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+	// lock every account before touching them, so the funds check below can't race with other transfers
+	accounts, err := lockAccountsInOrder(ctx, q, plan.accountIDs(arg)...)
+	if err != nil {
+		return res, err
+	}
 
-		if err != nil {
-			return err
-		}
+	fromAcc := accounts[arg.FromAccountID]
+	if err := checkAccountActive(fromAcc); err != nil {
+		return res, err
+	}
+
+	if err := checkAccountActive(accounts[arg.ToAccountID]); err != nil {
+		return res, err
+	}
 
-		// update accounts' balances
-		// important! always update accounts in the same order to avoid deadlocking concurrent transactions
-		if arg.FromAccountID < arg.ToAccountID {
-			res.FromAccount, res.ToAccount, err = addAmountToBalance(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			res.ToAccount, res.FromAccount, err = addAmountToBalance(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+	if fee.Amount > 0 {
+		if err := checkFeeAccount(accounts[fee.AccountID], fromAcc); err != nil {
+			return res, err
 		}
+	}
 
-		if err != nil {
-			return err
+	// refunds give money back, they aren't held to the sender's limits
+	if !arg.ReversalOf.Valid {
+		if err := checkTransferLimits(ctx, q, fromAcc, arg.Amount, time.Now()); err != nil {
+			return res, err
 		}
+	}
 
-		return nil
+	if err := checkFunds(fromAcc, arg.Amount+fee.Amount); err != nil {
+		return res, err
+	}
+
+	// create transfer record
+	res.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ReversalOf:    arg.ReversalOf,
+		ToAmount:      arg.ToAmount,
+		FxRate:        arg.FxRate,
+		BatchID:       arg.BatchID,
+		Fee:           fee.Amount,
+		FeeAccountID:  sql.NullInt64{Int64: fee.AccountID, Valid: fee.Amount > 0},
 	})
 
-	return res, err
-}
+	if err != nil {
+		return res, err
+	}
+
+	// the amount pair comes first, then the fee pair, then the legs through the house
+	postings := []Posting{
+		{AccountID: arg.FromAccountID, Amount: -arg.Amount},
+		{AccountID: arg.ToAccountID, Amount: arg.ToAmount},
+	}
+
+	if fee.Amount > 0 {
+		postings = append(postings,
+			Posting{AccountID: arg.FromAccountID, Amount: -fee.Amount},
+			Posting{AccountID: fee.AccountID, Amount: fee.Amount},
+		)
+		res.Fee = fee.Amount
+	}
+
+	postings = append(postings, plan.fx...)
 
-func addAmountToBalance(
-	ctx context.Context,
-	q *Queries,
-	acc1ID int64,
-	amount1 int64,
-	acc2ID int64,
-	amount2 int64,
-) (acc1 Account, acc2 Account, err error) {
-	acc1, err = q.UpdateAccountBalance(ctx, UpdateAccountBalanceParams{
-		ID:     acc1ID,
-		Amount: amount1,
+	entries, err := postEntries(ctx, q, accounts, postings, CreateEntryParams{
+		TransferID: sql.NullInt64{Int64: res.Transfer.ID, Valid: true},
 	})
+	if err != nil {
+		return res, err
+	}
+	res.FromEntry, res.ToEntry = entries[0], entries[1]
+
+	deltas := postingDeltas(postings)
 
+	if err := publishTransferEvents(ctx, q, res.Transfer, accounts, deltas); err != nil {
+		return res, err
+	}
+
+	if err := recordAudit(ctx, q, AuditTransferCreate, res.Transfer.ID, nil, res.Transfer); err != nil {
+		return res, err
+	}
+
+	res.FromAccount = accounts[arg.FromAccountID]
+	res.ToAccount = accounts[arg.ToAccountID]
+	return res, nil
+}
+
+// transferPlan is what a transfer needs besides its own two accounts, known before they are locked
+type transferPlan struct {
+	fee transferFee
+	// fx carries the legs of a cross-currency transfer through the asset house account of each currency
+	fx []Posting
+}
+
+// planTransfer finds the fee of a transfer and, across currencies, the house accounts it goes through
+func planTransfer(ctx context.Context, q *Queries, arg TransferTxParams) (transferPlan, error) {
+	var plan transferPlan
+	var err error
+
+	arg = withDestinationLeg(arg)
+
+	plan.fee, err = planFee(ctx, q, arg)
+	if err != nil {
+		return plan, err
+	}
+
+	fromAcc, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return plan, err
+	}
+
+	toAcc, err := q.GetAccount(ctx, arg.ToAccountID)
+	if err != nil {
+		return plan, err
+	}
+
+	if fromAcc.Currency == toAcc.Currency {
+		return plan, nil
+	}
+
+	fromHouse, err := houseAccount(ctx, q, fromAcc.Currency, AccountKindAsset)
+	if err != nil {
+		return plan, err
+	}
+
+	toHouse, err := houseAccount(ctx, q, toAcc.Currency, AccountKindAsset)
+	if err != nil {
+		return plan, err
+	}
+
+	// the house buys the amount in the source currency and pays out the converted one
+	plan.fx = []Posting{
+		{AccountID: fromHouse.ID, Amount: arg.Amount},
+		{AccountID: toHouse.ID, Amount: -arg.ToAmount},
+	}
+	return plan, nil
+}
+
+// accountIDs returns every account the transfer posts to, to lock them together
+func (plan transferPlan) accountIDs(arg TransferTxParams) []int64 {
+	ids := []int64{arg.FromAccountID, arg.ToAccountID, plan.fee.AccountID}
+	for _, posting := range plan.fx {
+		ids = append(ids, posting.AccountID)
+	}
+	return ids
+}
+
+// withDestinationLeg fills in the destination leg that same-currency callers may leave out
+func withDestinationLeg(arg TransferTxParams) TransferTxParams {
+	if arg.ToAmount == 0 {
+		arg.ToAmount = arg.Amount
+		arg.FxRate = util.FXRateScale
+	}
+	return arg
+}
+
+// lockActiveAccounts locks both sides of a transfer and checks that they can transact
+func lockActiveAccounts(ctx context.Context, q *Queries, fromAccountID int64, toAccountID int64) (fromAcc Account, toAcc Account, err error) {
+	accounts, err := lockAccountsInOrder(ctx, q, fromAccountID, toAccountID)
 	if err != nil {
 		return
 	}
 
-	acc2, err = q.UpdateAccountBalance(ctx, UpdateAccountBalanceParams{
-		ID:     acc2ID,
-		Amount: amount2,
-	})
+	fromAcc, toAcc = accounts[fromAccountID], accounts[toAccountID]
+
+	if err = checkAccountActive(fromAcc); err != nil {
+		return
+	}
 
+	err = checkAccountActive(toAcc)
 	return
 }
+
+// lockAccountsInOrder locks the distinct accounts among ids, zero ids are skipped.
+// important! accounts are locked in id order, the same as the balance updates, to avoid deadlocks
+func lockAccountsInOrder(ctx context.Context, q *Queries, ids ...int64) (map[int64]Account, error) {
+	accounts := make(map[int64]Account, len(ids))
+	for _, id := range ids {
+		if id != 0 {
+			accounts[id] = Account{}
+		}
+	}
+
+	for _, id := range sortedAccountIDs(accounts) {
+		acc, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		accounts[id] = acc
+	}
+
+	return accounts, nil
+}
+
+// sortedAccountIDs returns the keys of accounts in ascending order
+func sortedAccountIDs[V any](accounts map[int64]V) []int64 {
+	ids := make([]int64, 0, len(accounts))
+	for id := range accounts {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// checkFunds makes sure the available balance and the overdraft limit cover a debit of amount
+func checkFunds(acc Account, amount int64) error {
+	if available := acc.AvailableBalance + acc.OverdraftLimit; available < amount {
+		return &InsufficientFundsError{
+			AccountID: acc.ID,
+			Available: available,
+			Requested: amount,
+		}
+	}
+	return nil
+}
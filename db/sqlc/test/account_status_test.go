@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeAccountStatusTx(t *testing.T) {
+	store := db.NewStore(testDB)
+	acc := createFundedAccount(t, 0)
+	require.Equal(t, db.AccountStatusActive, acc.Status)
+
+	frozen, err := store.ChangeAccountStatusTx(context.Background(), db.ChangeAccountStatusTxParams{
+		AccountID: acc.ID,
+		Status:    db.AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.AccountStatusFrozen, frozen.Status)
+
+	// freezing twice isn't a valid transition
+	_, err = store.ChangeAccountStatusTx(context.Background(), db.ChangeAccountStatusTxParams{
+		AccountID: acc.ID,
+		Status:    db.AccountStatusFrozen,
+	})
+	require.ErrorIs(t, err, db.ErrInvalidStatusTransition)
+
+	closed, err := store.ChangeAccountStatusTx(context.Background(), db.ChangeAccountStatusTxParams{
+		AccountID: acc.ID,
+		Status:    db.AccountStatusClosed,
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.AccountStatusClosed, closed.Status)
+
+	// a closed account can't be reopened
+	_, err = store.ChangeAccountStatusTx(context.Background(), db.ChangeAccountStatusTxParams{
+		AccountID: acc.ID,
+		Status:    db.AccountStatusActive,
+	})
+	require.ErrorIs(t, err, db.ErrInvalidStatusTransition)
+}
+
+func TestCloseAccountWithBalance(t *testing.T) {
+	store := db.NewStore(testDB)
+	acc := createFundedAccount(t, 50)
+
+	_, err := store.ChangeAccountStatusTx(context.Background(), db.ChangeAccountStatusTxParams{
+		AccountID: acc.ID,
+		Status:    db.AccountStatusClosed,
+	})
+	require.ErrorIs(t, err, db.ErrAccountNotEmpty)
+
+	var notEmptyErr *db.AccountNotEmptyError
+	require.ErrorAs(t, err, &notEmptyErr)
+	require.Equal(t, int64(50), notEmptyErr.Balance)
+
+	acc2, err := testQueries.GetAccount(context.Background(), acc.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.AccountStatusActive, acc2.Status)
+}
+
+func TestCloseAccountWithPendingHold(t *testing.T) {
+	store := db.NewStore(testDB)
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+
+	authorizeHold(t, store, acc1, acc2, 40, time.Now().Add(time.Hour))
+
+	// the recipient has no balance, but the pending hold still pays into it
+	_, err := store.ChangeAccountStatusTx(context.Background(), db.ChangeAccountStatusTxParams{
+		AccountID: acc2.ID,
+		Status:    db.AccountStatusClosed,
+	})
+	require.ErrorIs(t, err, db.ErrAccountInUse)
+
+	var inUseErr *db.AccountInUseError
+	require.ErrorAs(t, err, &inUseErr)
+	require.Equal(t, int64(1), inUseErr.PendingHolds)
+
+	acc2, err = testQueries.GetAccount(context.Background(), acc2.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.AccountStatusActive, acc2.Status)
+}
+
+func TestTransferTxInactiveAccount(t *testing.T) {
+	store := db.NewStore(testDB)
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+
+	_, err := store.ChangeAccountStatusTx(context.Background(), db.ChangeAccountStatusTxParams{
+		AccountID: acc1.ID,
+		Status:    db.AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, db.ErrAccountFrozen)
+
+	_, err = store.ChangeAccountStatusTx(context.Background(), db.ChangeAccountStatusTxParams{
+		AccountID: acc2.ID,
+		Status:    db.AccountStatusClosed,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc2.ID,
+		ToAccountID:   acc1.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, db.ErrAccountClosed)
+
+	acc1, err = testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), acc1.Balance)
+}
@@ -13,8 +13,10 @@ import (
 )
 
 func createRandomAccount(t *testing.T) db.Account {
+	user := createRandomUser(t)
+
 	arg := db.CreateAccountParams{
-		Owner:    util.RandomOwner(),
+		Owner:    user.Username,
 		Balance:  util.RandomAmount(),
 		Currency: util.RandomCurrency(),
 	}
@@ -79,20 +81,23 @@ func TestDeleteAccount(t *testing.T) {
 }
 
 func TestListAccounts(t *testing.T) {
+	var last db.Account
 	for i := 0; i < 10; i++ {
-		createRandomAccount(t)
+		last = createRandomAccount(t)
 	}
 
 	arg := db.ListAccountsParams{
+		Owner:  last.Owner,
 		Limit:  5,
-		Offset: 5,
+		Offset: 0,
 	}
 
 	accs, err := testQueries.ListAccounts(context.Background(), arg)
 	require.NoError(t, err)
-	require.Len(t, accs, 5)
+	require.NotEmpty(t, accs)
 
 	for _, acc := range accs {
 		require.NotEmpty(t, acc)
+		require.Equal(t, last.Owner, acc.Owner)
 	}
 }
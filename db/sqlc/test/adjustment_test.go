@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	db "simplebank/db/sqlc"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createSuspenseAccount makes a new account the suspense account of currency
+func createSuspenseAccount(t *testing.T, currency string) db.Account {
+	acc := createCurrencyAccount(t, currency, 0)
+
+	_, err := testQueries.UpsertSuspenseAccount(context.Background(), db.UpsertSuspenseAccountParams{
+		Currency:  currency,
+		AccountID: acc.ID,
+	})
+	require.NoError(t, err)
+
+	return acc
+}
+
+// adjustAccount corrects the balance of acc by amount
+func adjustAccount(t *testing.T, acc db.Account, amount int64) db.AdjustAccountTxResult {
+	createSuspenseAccount(t, acc.Currency)
+
+	res, err := db.NewStore(testDB).AdjustAccountTx(context.Background(), db.AdjustAccountTxParams{
+		AccountID:  acc.ID,
+		Kind:       db.AdjustmentCorrection,
+		Amount:     amount,
+		ReasonCode: "posting_error",
+		CreatedBy:  acc.Owner,
+	})
+	require.NoError(t, err)
+
+	return res
+}
+
+func TestAdjustAccountTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc := createFundedAccount(t, 100)
+	suspense := createSuspenseAccount(t, acc.Currency)
+
+	res, err := store.AdjustAccountTx(context.Background(), db.AdjustAccountTxParams{
+		AccountID:  acc.ID,
+		Kind:       db.AdjustmentWithdrawal,
+		Amount:     40,
+		ReasonCode: "cash",
+		Note:       "counter withdrawal",
+		CreatedBy:  acc.Owner,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(-40), res.Adjustment.Amount)
+	require.Equal(t, suspense.ID, res.Adjustment.SuspenseAccountID)
+	require.Equal(t, "counter withdrawal", res.Adjustment.Note)
+	require.Equal(t, int64(60), res.Account.Balance)
+
+	// the entries balance each other
+	require.Equal(t, acc.ID, res.Entry.AccountID)
+	require.Equal(t, int64(-40), res.Entry.Amount)
+	require.Equal(t, suspense.ID, res.SuspenseEntry.AccountID)
+	require.Equal(t, int64(40), res.SuspenseEntry.Amount)
+	require.Equal(t, res.Adjustment.ID, res.Entry.AdjustmentID.Int64)
+	require.Equal(t, res.Adjustment.ID, res.SuspenseEntry.AdjustmentID.Int64)
+
+	updatedSuspense, err := testQueries.GetAccount(context.Background(), suspense.ID)
+	require.NoError(t, err)
+	require.Equal(t, suspense.Balance+40, updatedSuspense.Balance)
+
+	adjustments, err := store.ListAdjustments(context.Background(), db.ListAdjustmentsParams{
+		AccountID: acc.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, adjustments, 1)
+	require.Equal(t, res.Adjustment.ID, adjustments[0].ID)
+
+	// the statements show each side of the adjustment against the other
+	entries, err := store.ListStatementEntries(context.Background(), db.ListStatementEntriesParams{
+		AccountID: acc.ID,
+		FromTime:  res.Entry.CreatedAt.Add(-time.Second),
+		ToTime:    res.Entry.CreatedAt.Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, suspense.ID, entries[0].CounterpartyAccountID.Int64)
+
+	entries, err = store.ListStatementEntries(context.Background(), db.ListStatementEntriesParams{
+		AccountID: suspense.ID,
+		FromTime:  res.SuspenseEntry.CreatedAt.Add(-time.Second),
+		ToTime:    res.SuspenseEntry.CreatedAt.Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, acc.ID, entries[0].CounterpartyAccountID.Int64)
+
+	rows, err := store.ListAuditLog(context.Background(), db.ListAuditLogParams{
+		Operation:  db.AuditAdjustmentCreate,
+		EntityID:   strconv.FormatInt(res.Adjustment.ID, 10),
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+}
+
+func TestAdjustAccountTxErrors(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc := createFundedAccount(t, 10)
+	createSuspenseAccount(t, acc.Currency)
+
+	testCases := []struct {
+		name  string
+		arg   db.AdjustAccountTxParams
+		check func(t *testing.T, err error)
+	}{
+		{
+			name: "NegativeDeposit",
+			arg:  db.AdjustAccountTxParams{Kind: db.AdjustmentDeposit, Amount: -5, ReasonCode: "cash"},
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, db.ErrInvalidAdjustment)
+			},
+		},
+		{
+			name: "WrongReasonCode",
+			arg:  db.AdjustAccountTxParams{Kind: db.AdjustmentCorrection, Amount: 5, ReasonCode: "cash"},
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, db.ErrInvalidAdjustment)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			arg:  db.AdjustAccountTxParams{Kind: db.AdjustmentWithdrawal, Amount: 50, ReasonCode: "wire"},
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, db.ErrInsufficientFunds)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.arg.AccountID = acc.ID
+			tc.arg.CreatedBy = acc.Owner
+
+			_, err := store.AdjustAccountTx(context.Background(), tc.arg)
+			tc.check(t, err)
+
+			updated, err := testQueries.GetAccount(context.Background(), acc.ID)
+			require.NoError(t, err)
+			require.Equal(t, acc.Balance, updated.Balance)
+		})
+	}
+}
+
+func TestAdjustFrozenAccount(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc := createFundedAccount(t, 10)
+	createSuspenseAccount(t, acc.Currency)
+
+	_, err := store.ChangeAccountStatusTx(context.Background(), db.ChangeAccountStatusTxParams{
+		AccountID: acc.ID,
+		Status:    db.AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+
+	_, err = store.AdjustAccountTx(context.Background(), db.AdjustAccountTxParams{
+		AccountID:  acc.ID,
+		Kind:       db.AdjustmentDeposit,
+		Amount:     5,
+		ReasonCode: "cash",
+		CreatedBy:  acc.Owner,
+	})
+	require.ErrorIs(t, err, db.ErrAccountFrozen)
+
+	// corrections still apply to frozen accounts
+	res := adjustAccount(t, acc, -3)
+	require.Equal(t, int64(7), res.Account.Balance)
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	db "simplebank/db/sqlc"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferAuditLog(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+
+	ctx := db.WithAuditActor(context.Background(), db.AuditActor{
+		Username:  acc1.Owner,
+		RequestID: "req-" + strconv.FormatInt(acc1.ID, 10),
+		ClientIP:  "192.0.2.1",
+	})
+
+	res, err := store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+
+	rows, err := store.ListAuditLog(context.Background(), db.ListAuditLogParams{
+		EntityType: "transfer",
+		EntityID:   strconv.FormatInt(res.Transfer.ID, 10),
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+
+	row := rows[0]
+	require.Equal(t, acc1.Owner, row.Actor)
+	require.Equal(t, "req-"+strconv.FormatInt(acc1.ID, 10), row.RequestID)
+	require.Equal(t, "192.0.2.1", row.ClientIp)
+	require.Equal(t, db.AuditTransferCreate, row.Operation)
+	require.JSONEq(t, "null", string(row.Before))
+	require.Len(t, row.Hash, 64)
+
+	var after db.Transfer
+	require.NoError(t, json.Unmarshal(row.After, &after))
+	require.Equal(t, res.Transfer.ID, after.ID)
+	require.Equal(t, int64(30), after.Amount)
+}
+
+func TestAuditLogRollback(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 10)
+	acc2 := createFundedAccount(t, 0)
+
+	// makes sure the log isn't empty
+	adjustAccount(t, acc1, 5)
+
+	last, err := testQueries.GetLastAuditLogHash(context.Background())
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        30,
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	// a change that isn't committed isn't recorded
+	hash, err := testQueries.GetLastAuditLogHash(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, last, hash)
+}
+
+func TestAuditedStoreMethods(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc := createFundedAccount(t, 10)
+
+	suspense, err := store.UpsertSuspenseAccount(context.Background(), db.UpsertSuspenseAccountParams{
+		Currency:  acc.Currency,
+		AccountID: acc.ID,
+	})
+	require.NoError(t, err)
+
+	rows, err := store.ListAuditLog(context.Background(), db.ListAuditLogParams{
+		Operation:  db.AuditSuspenseAccountUpsert,
+		EntityID:   suspense.Currency,
+		LimitCount: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, db.AuditActorSystem, rows[0].Actor)
+
+	var after db.SuspenseAccount
+	require.NoError(t, json.Unmarshal(rows[0].After, &after))
+	require.Equal(t, acc.ID, after.AccountID)
+
+	webhook, err := store.CreateWebhook(context.Background(), db.CreateWebhookParams{
+		Owner:      acc.Owner,
+		Url:        "http://localhost:8081/hooks",
+		Secret:     "whsec_test",
+		EventTypes: []string{},
+	})
+	require.NoError(t, err)
+
+	rows, err = store.ListAuditLog(context.Background(), db.ListAuditLogParams{
+		Operation:  db.AuditWebhookCreate,
+		EntityID:   strconv.FormatInt(webhook.ID, 10),
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.NotContains(t, string(rows[0].After), "whsec_test")
+}
+
+func TestAuditLogAppendOnly(t *testing.T) {
+	acc := createFundedAccount(t, 10)
+	adjustAccount(t, acc, 5)
+
+	_, err := testDB.Exec(`UPDATE audit_log SET actor = 'someone' WHERE id = (SELECT max(id) FROM audit_log)`)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = testDB.Exec(`DELETE FROM audit_log WHERE id = (SELECT max(id) FROM audit_log)`)
+	require.ErrorContains(t, err, "append-only")
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+
+	for i := 0; i < 3; i++ {
+		_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: acc1.ID,
+			ToAccountID:   acc2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+	}
+
+	last, err := testQueries.GetLastAuditLogHash(context.Background())
+	require.NoError(t, err)
+
+	res, err := store.VerifyAuditLog(context.Background(), db.VerifyAuditLogParams{ChunkSize: 2})
+	require.NoError(t, err)
+	require.Empty(t, res.Breaks)
+	require.GreaterOrEqual(t, res.RowsChecked, int64(3))
+	require.Equal(t, last, res.LastHash)
+}
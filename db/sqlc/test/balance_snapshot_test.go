@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func balanceAt(t *testing.T, acc db.Account, asOf time.Time) db.GetBalanceAtRow {
+	balance, err := testQueries.GetBalanceAt(context.Background(), db.GetBalanceAtParams{
+		AccountID: acc.ID,
+		AsOf:      asOf.UTC(),
+	})
+	require.NoError(t, err)
+
+	return balance
+}
+
+func TestGetBalanceAt(t *testing.T) {
+	acc := createCurrencyAccount(t, util.USD, 0)
+
+	before := time.Now()
+	adjustAccount(t, acc, 100)
+	between := time.Now()
+	adjustAccount(t, acc, 50)
+
+	require.Zero(t, balanceAt(t, acc, before).Balance)
+	require.Equal(t, int64(100), balanceAt(t, acc, between).Balance)
+	require.Equal(t, int64(150), balanceAt(t, acc, time.Now()).Balance)
+}
+
+func TestSnapshotBalances(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc := createCurrencyAccount(t, util.USD, 0)
+
+	before := time.Now()
+	adjustAccount(t, acc, 100)
+	takenAt := time.Now()
+	adjustAccount(t, acc, 50)
+
+	res, err := store.SnapshotBalances(context.Background(), db.SnapshotBalancesParams{
+		TakenAt:   takenAt,
+		ChunkSize: 50,
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, res.AccountsSnapshotted, int64(1))
+
+	// balances after the snapshot start from it, those before sum every entry
+	balance := balanceAt(t, acc, time.Now())
+	require.Equal(t, int64(150), balance.Balance)
+	require.True(t, balance.SnapshotTakenAt.Valid)
+	require.WithinDuration(t, takenAt, balance.SnapshotTakenAt.Time, time.Millisecond)
+
+	balance = balanceAt(t, acc, takenAt)
+	require.Equal(t, int64(100), balance.Balance)
+	require.True(t, balance.SnapshotTakenAt.Valid)
+
+	balance = balanceAt(t, acc, before)
+	require.Zero(t, balance.Balance)
+	require.False(t, balance.SnapshotTakenAt.Valid)
+
+	// the next snapshot starts from this one
+	adjustAccount(t, acc, -30)
+	nextTakenAt := time.Now()
+
+	_, err = store.SnapshotBalances(context.Background(), db.SnapshotBalancesParams{
+		TakenAt: nextTakenAt,
+	})
+	require.NoError(t, err)
+
+	balance = balanceAt(t, acc, time.Now())
+	require.Equal(t, int64(120), balance.Balance)
+	require.WithinDuration(t, nextTakenAt, balance.SnapshotTakenAt.Time, time.Millisecond)
+}
+
+func TestSnapshotBalancesNotSettled(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	// an open transaction may still write entries dated from its start
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+
+	takenAt := time.Now()
+	_, err = store.SnapshotBalances(context.Background(), db.SnapshotBalancesParams{
+		TakenAt: takenAt,
+	})
+	require.ErrorIs(t, err, db.ErrSnapshotNotSettled)
+
+	require.NoError(t, tx.Rollback())
+
+	_, err = store.SnapshotBalances(context.Background(), db.SnapshotBalancesParams{
+		TakenAt: takenAt,
+	})
+	require.NoError(t, err)
+}
+
+func TestRebuildBalanceSnapshots(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc := createCurrencyAccount(t, util.USD, 0)
+	adjustAccount(t, acc, 100)
+
+	takenAt := time.Now()
+	_, err := store.SnapshotBalances(context.Background(), db.SnapshotBalancesParams{
+		TakenAt: takenAt,
+	})
+	require.NoError(t, err)
+
+	// an account opened after the snapshot joins the rebuilt one only if it existed by then
+	later := createCurrencyAccount(t, util.USD, 0)
+
+	res, err := store.RebuildBalanceSnapshots(context.Background(), db.RebuildBalanceSnapshotsParams{
+		From: takenAt.Add(-time.Millisecond),
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, res.Snapshots, int64(1))
+	require.GreaterOrEqual(t, res.AccountsSnapshotted, int64(1))
+
+	balance := balanceAt(t, acc, takenAt)
+	require.Equal(t, int64(100), balance.Balance)
+	require.True(t, balance.SnapshotTakenAt.Valid)
+
+	require.False(t, balanceAt(t, later, time.Now()).SnapshotTakenAt.Valid)
+}
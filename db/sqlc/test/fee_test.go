@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createFeeSchedule puts payer on a tier of its own and charges that tier with schedule,
+// so the fees don't leak into other tests. The fees are paid to a new house account.
+func createFeeSchedule(t *testing.T, payer db.Account, schedule db.UpsertFeeScheduleParams) (db.Account, db.FeeSchedule) {
+	tier := util.RandomString(8)
+
+	_, err := testQueries.UpdateAccountTier(context.Background(), db.UpdateAccountTierParams{
+		ID:   payer.ID,
+		Tier: tier,
+	})
+	require.NoError(t, err)
+
+	house, err := testQueries.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: payer.Currency,
+	})
+	require.NoError(t, err)
+
+	schedule.Currency = payer.Currency
+	schedule.Tier = tier
+	schedule.FeeAccountID = house.ID
+
+	feeSchedule, err := testQueries.UpsertFeeSchedule(context.Background(), schedule)
+	require.NoError(t, err)
+
+	return house, feeSchedule
+}
+
+func TestTransferTxWithFee(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	payer := createFundedAccount(t, 1000)
+	payee := createFundedAccount(t, 0)
+	house, _ := createFeeSchedule(t, payer, db.UpsertFeeScheduleParams{
+		FlatFee:       5,
+		PercentageBps: 100,
+	})
+
+	res, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   payee.ID,
+		Amount:        500,
+	})
+	require.NoError(t, err)
+
+	// 5 + 1% of 500
+	require.Equal(t, int64(10), res.Fee)
+	require.Equal(t, int64(10), res.Transfer.Fee)
+	require.Equal(t, int64(490), res.FromAccount.Balance)
+	require.Equal(t, int64(500), res.ToAccount.Balance)
+	require.Equal(t, int64(-500), res.FromEntry.Amount)
+
+	house, err = testQueries.GetAccount(context.Background(), house.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), house.Balance)
+
+	entries, err := testQueries.ListEntries(context.Background(), db.ListEntriesParams{
+		AccountID: payer.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, int64(-10), entries[1].Amount)
+
+	// the fee counts towards the funds check
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   payee.ID,
+		Amount:        490,
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+}
+
+func TestQuoteTransfer(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	payer := createFundedAccount(t, 0)
+	payee := createFundedAccount(t, 0)
+	createFeeSchedule(t, payer, db.UpsertFeeScheduleParams{
+		PercentageBps: 250,
+		MinFee:        30,
+		MaxFee:        100,
+	})
+
+	quote, err := store.QuoteTransfer(context.Background(), db.TransferTxParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   payee.ID,
+		Amount:        1000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), quote.Fee)
+	require.Equal(t, int64(1030), quote.TotalDebit)
+	require.Equal(t, int64(1000), quote.ToAmount)
+
+	// quoting doesn't move money
+	payer, err = testQueries.GetAccount(context.Background(), payer.ID)
+	require.NoError(t, err)
+	require.Zero(t, payer.Balance)
+}
+
+func TestComputeFee(t *testing.T) {
+	schedule := db.FeeSchedule{
+		FlatFee:       10,
+		PercentageBps: 150,
+		MinFee:        25,
+		MaxFee:        200,
+	}
+
+	require.Equal(t, int64(25), db.ComputeFee(schedule, 100))
+	// 10 + 1.5% of 2000
+	require.Equal(t, int64(40), db.ComputeFee(schedule, 2000))
+	require.Equal(t, int64(200), db.ComputeFee(schedule, 100_000))
+
+	schedule.MaxFee = 0
+	require.Equal(t, int64(1510), db.ComputeFee(schedule, 100_000))
+}
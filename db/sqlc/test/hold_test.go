@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func authorizeHold(t *testing.T, store db.Store, from db.Account, to db.Account, amount int64, expiresAt time.Time) db.Hold {
+	res, err := store.AuthorizeTx(context.Background(), db.AuthorizeTxParams{
+		TransferTxParams: db.TransferTxParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+		},
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.HoldStatusPending, res.Hold.Status)
+	require.Equal(t, amount, res.Hold.Amount)
+	require.Equal(t, amount, res.Hold.ToAmount)
+
+	return res.Hold
+}
+
+func TestAuthorizeTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+
+	authorizeHold(t, store, acc1, acc2, 70, time.Now().Add(time.Hour))
+
+	acc1, err := testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), acc1.Balance)
+	require.Equal(t, int64(70), acc1.HeldAmount)
+	require.Equal(t, int64(30), acc1.AvailableBalance)
+
+	// the hold can't be spent twice
+	_, err = store.AuthorizeTx(context.Background(), db.AuthorizeTxParams{
+		TransferTxParams: db.TransferTxParams{FromAccountID: acc1.ID, ToAccountID: acc2.ID, Amount: 40},
+		ExpiresAt:        time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        40,
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+}
+
+func TestAuthorizeTxWithFee(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+	createFeeSchedule(t, acc1, db.UpsertFeeScheduleParams{FlatFee: 5})
+
+	// 96 + 5 of fee is more than the balance
+	_, err := store.AuthorizeTx(context.Background(), db.AuthorizeTxParams{
+		TransferTxParams: db.TransferTxParams{FromAccountID: acc1.ID, ToAccountID: acc2.ID, Amount: 96},
+		ExpiresAt:        time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	hold := authorizeHold(t, store, acc1, acc2, 70, time.Now().Add(time.Hour))
+	require.Equal(t, int64(5), hold.Fee)
+
+	acc1, err = testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(75), acc1.HeldAmount)
+
+	_, err = store.VoidHoldTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+
+	acc1, err = testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Zero(t, acc1.HeldAmount)
+}
+
+func TestCaptureHoldTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+	hold := authorizeHold(t, store, acc1, acc2, 70, time.Now().Add(time.Hour))
+
+	_, err := store.CaptureHoldTx(context.Background(), db.CaptureHoldTxParams{HoldID: hold.ID, Amount: 80})
+	require.ErrorIs(t, err, db.ErrCaptureExceedsHold)
+
+	res, err := store.CaptureHoldTx(context.Background(), db.CaptureHoldTxParams{HoldID: hold.ID, Amount: 50})
+	require.NoError(t, err)
+	require.Equal(t, db.HoldStatusCaptured, res.Hold.Status)
+	require.Equal(t, int64(50), res.Hold.CapturedAmount)
+	require.Equal(t, res.Transfer.ID, res.Hold.TransferID.Int64)
+	require.Equal(t, int64(50), res.Transfer.Amount)
+
+	// the uncaptured 20 are released
+	require.Equal(t, int64(50), res.FromAccount.Balance)
+	require.Zero(t, res.FromAccount.HeldAmount)
+	require.Equal(t, int64(50), res.FromAccount.AvailableBalance)
+	require.Equal(t, int64(50), res.ToAccount.Balance)
+
+	_, err = store.CaptureHoldTx(context.Background(), db.CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, db.ErrHoldNotPending)
+}
+
+func TestVoidHoldTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+	hold := authorizeHold(t, store, acc1, acc2, 70, time.Now().Add(time.Hour))
+
+	voided, err := store.VoidHoldTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.HoldStatusVoided, voided.Status)
+
+	acc1, err = testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), acc1.Balance)
+	require.Equal(t, int64(100), acc1.AvailableBalance)
+
+	_, err = store.VoidHoldTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, db.ErrHoldNotPending)
+}
+
+func TestExpireHoldTx(t *testing.T) {
+	store := db.NewStore(testDB)
+	now := dueTime()
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+	hold := authorizeHold(t, store, acc1, acc2, 70, now)
+
+	_, err := store.CaptureHoldTx(context.Background(), db.CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, db.ErrHoldExpired)
+
+	expired, err := store.ExpireHoldTx(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, hold.ID, expired.ID)
+	require.Equal(t, db.HoldStatusExpired, expired.Status)
+
+	acc1, err = testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Zero(t, acc1.HeldAmount)
+
+	_, err = store.ExpireHoldTx(context.Background(), now)
+	require.ErrorIs(t, err, db.ErrNoHoldExpired)
+}
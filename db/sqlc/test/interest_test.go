@@ -0,0 +1,248 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createSavingsAccount opens a USD account holding balance on a product paying balance/1000 a day,
+// accruing from today. Its entries are dated now, so only days from today on earn interest.
+func createSavingsAccount(t *testing.T, balance int64) (db.Account, db.AccountProduct) {
+	store := db.NewStore(testDB)
+
+	product, err := store.UpsertAccountProduct(context.Background(), db.UpsertAccountProductParams{
+		Name:        util.RandomString(12),
+		Currency:    util.USD,
+		RateBps:     3650,
+		DayCount:    util.DayCountACT365,
+		Compounding: util.CompoundingMonthly,
+	})
+	require.NoError(t, err)
+
+	acc := createCurrencyAccount(t, util.USD, 0)
+	res := adjustAccount(t, acc, balance)
+
+	savings, err := store.CreateSavingsAccount(context.Background(), db.CreateSavingsAccountParams{
+		AccountID:   acc.ID,
+		ProductID:   product.ID,
+		AccruesFrom: today(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, product.ID, savings.ProductID)
+
+	return res.Account, product
+}
+
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+func TestAccrueInterest(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc, _ := createSavingsAccount(t, 100_000)
+	through := today().AddDate(0, 0, 2)
+
+	res, err := store.AccrueInterest(context.Background(), db.AccrueInterestParams{
+		Through:   through,
+		ChunkSize: 2,
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, res.DaysAccrued, int64(3))
+
+	accruals, err := testQueries.ListInterestAccruals(context.Background(), db.ListInterestAccrualsParams{
+		AccountID: acc.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 3)
+
+	// near the end of the month, the month is capitalized before the next one accrues
+	monthEnd := time.Date(today().Year(), today().Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	capitalized := int64(monthEnd.Day()-today().Day()+1) * 100
+
+	for i, accrual := range accruals {
+		require.True(t, accrual.AccrualDate.Equal(through.AddDate(0, 0, -i)), accrual.AccrualDate)
+		if accrual.AccrualDate.After(monthEnd) {
+			require.Equal(t, int64(100_000)+capitalized, accrual.Balance)
+			continue
+		}
+		require.Equal(t, int64(100_000), accrual.Balance)
+		require.Equal(t, int64(100), accrual.Amount)
+		require.Equal(t, through.After(monthEnd), accrual.CapitalizedAt.Valid)
+	}
+
+	// every day through is accrued already, running again backfills nothing
+	res, err = store.AccrueInterest(context.Background(), db.AccrueInterestParams{
+		Through: through,
+	})
+	require.NoError(t, err)
+	require.Zero(t, res.DaysAccrued)
+
+	// a missed day is backfilled with the next run
+	_, err = store.AccrueInterest(context.Background(), db.AccrueInterestParams{
+		Through: through.AddDate(0, 0, 2),
+	})
+	require.NoError(t, err)
+
+	accruals, err = testQueries.ListInterestAccruals(context.Background(), db.ListInterestAccrualsParams{
+		AccountID: acc.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 5)
+}
+
+func TestCapitalizeInterest(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc, _ := createSavingsAccount(t, 100_000)
+	expense := houseAccount(t, util.USD, db.AccountKindExpense)
+
+	now := today()
+	monthEnd := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	days := int64(monthEnd.Day() - now.Day() + 1)
+
+	// the month isn't fully accrued yet
+	_, err := store.AccrueInterest(context.Background(), db.AccrueInterestParams{
+		Through: monthEnd.AddDate(0, 0, -1),
+	})
+	require.NoError(t, err)
+
+	_, err = store.CapitalizeInterest(context.Background(), db.CapitalizeInterestParams{
+		Through: monthEnd,
+	})
+	require.NoError(t, err)
+
+	unchanged, err := testQueries.GetAccount(context.Background(), acc.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc.Balance, unchanged.Balance)
+
+	_, err = store.AccrueInterest(context.Background(), db.AccrueInterestParams{
+		Through: monthEnd,
+	})
+	require.NoError(t, err)
+
+	res, err := store.CapitalizeInterest(context.Background(), db.CapitalizeInterestParams{
+		Through: monthEnd,
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, res.AccountsCapitalized, int64(1))
+	require.GreaterOrEqual(t, res.AccrualsCapitalized, days)
+
+	capitalized, err := testQueries.GetAccount(context.Background(), acc.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc.Balance+days*100, capitalized.Balance)
+
+	accruals, err := testQueries.ListInterestAccruals(context.Background(), db.ListInterestAccrualsParams{
+		AccountID: acc.ID,
+		Limit:     31,
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, int(days))
+
+	journalID := accruals[0].JournalID
+	require.True(t, journalID.Valid)
+	for _, accrual := range accruals {
+		require.True(t, accrual.CapitalizedAt.Valid)
+		require.Equal(t, journalID, accrual.JournalID)
+	}
+
+	entries, err := testQueries.ListJournalEntries(context.Background(), journalID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, acc.ID, entries[0].AccountID)
+	require.Equal(t, days*100, entries[0].Amount)
+	require.Equal(t, expense.ID, entries[1].AccountID)
+	require.Equal(t, -days*100, entries[1].Amount)
+
+	// capitalized accruals are never posted twice
+	_, err = store.CapitalizeInterest(context.Background(), db.CapitalizeInterestParams{
+		Through: monthEnd,
+	})
+	require.NoError(t, err)
+
+	again, err := testQueries.GetAccount(context.Background(), acc.ID)
+	require.NoError(t, err)
+	require.Equal(t, capitalized.Balance, again.Balance)
+}
+
+func TestAccrueInterestCompoundsPerPeriod(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc, _ := createSavingsAccount(t, 100_000)
+
+	now := today()
+	monthEnd := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	days := int64(monthEnd.Day() - now.Day() + 1)
+
+	// catching up past the end of the month capitalizes it before the next month accrues
+	res, err := store.AccrueInterest(context.Background(), db.AccrueInterestParams{
+		Through: monthEnd.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, res.AccrualsCapitalized, days)
+
+	accruals, err := testQueries.ListInterestAccruals(context.Background(), db.ListInterestAccrualsParams{
+		AccountID: acc.ID,
+		Limit:     1,
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 1)
+	require.True(t, accruals[0].AccrualDate.Equal(monthEnd.AddDate(0, 0, 1)))
+	require.Equal(t, acc.Balance+days*100, accruals[0].Balance)
+	require.False(t, accruals[0].CapitalizedAt.Valid)
+
+	// the interest is dated on the last day of the month it was earned in
+	entries, err := testQueries.ListEntries(context.Background(), db.ListEntriesParams{
+		AccountID: acc.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+
+	interest := entries[len(entries)-1]
+	require.Equal(t, days*100, interest.Amount)
+	require.True(t, accrualDate(interest.CreatedAt).Equal(monthEnd), interest.CreatedAt)
+}
+
+func accrualDate(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+func TestCapitalizeInterestWithoutInterest(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc, _ := createSavingsAccount(t, 0)
+
+	now := today()
+	monthEnd := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+
+	_, err := store.AccrueInterest(context.Background(), db.AccrueInterestParams{
+		Through: monthEnd,
+	})
+	require.NoError(t, err)
+
+	_, err = store.CapitalizeInterest(context.Background(), db.CapitalizeInterestParams{
+		Through: monthEnd,
+	})
+	require.NoError(t, err)
+
+	accruals, err := testQueries.ListInterestAccruals(context.Background(), db.ListInterestAccrualsParams{
+		AccountID: acc.ID,
+		Limit:     31,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, accruals)
+
+	for _, accrual := range accruals {
+		require.Zero(t, accrual.Amount)
+		require.True(t, accrual.CapitalizedAt.Valid)
+		require.Equal(t, sql.NullInt64{}, accrual.JournalID)
+	}
+}
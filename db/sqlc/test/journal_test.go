@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func houseAccount(t *testing.T, currency string, kind string) db.Account {
+	acc, err := testQueries.GetHouseAccount(context.Background(), db.GetHouseAccountParams{
+		Currency: currency,
+		Kind:     kind,
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.HouseOwner, acc.Owner)
+
+	return acc
+}
+
+func TestSeedHouseAccountsTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	// TestMain already seeded every currency
+	created, err := store.SeedHouseAccountsTx(context.Background(), []string{util.USD, util.EUR})
+	require.NoError(t, err)
+	require.Empty(t, created)
+
+	for _, kind := range db.HouseAccountKinds {
+		acc := houseAccount(t, util.USD, kind)
+		require.Equal(t, kind, acc.Kind)
+		require.Equal(t, util.USD, acc.Currency)
+	}
+}
+
+func TestPostJournal(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc := createCurrencyAccount(t, util.USD, 0)
+	cash := houseAccount(t, util.USD, db.AccountKindAsset)
+	expense := houseAccount(t, util.USD, db.AccountKindExpense)
+
+	// a deposit of 100 and a bonus of 5 paid by the bank
+	res, err := store.PostJournal(context.Background(), []db.Posting{
+		{AccountID: acc.ID, Amount: 100},
+		{AccountID: cash.ID, Amount: -100},
+		{AccountID: acc.ID, Amount: 5},
+		{AccountID: expense.ID, Amount: -5},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Entries, 4)
+	require.Len(t, res.Accounts, 3)
+
+	for i, entry := range res.Entries {
+		require.Equal(t, sql.NullInt64{Int64: res.Journal.ID, Valid: true}, entry.JournalID)
+		require.False(t, entry.TransferID.Valid, "entry %d", i)
+	}
+
+	updated, err := testQueries.GetAccount(context.Background(), acc.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(105), updated.Balance)
+
+	updatedCash, err := testQueries.GetAccount(context.Background(), cash.ID)
+	require.NoError(t, err)
+	require.Equal(t, cash.Balance-100, updatedCash.Balance)
+
+	entries, err := testQueries.ListJournalEntries(context.Background(), sql.NullInt64{Int64: res.Journal.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+}
+
+func TestPostJournalErrors(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	usdAcc := createCurrencyAccount(t, util.USD, 10)
+	eurAcc := createCurrencyAccount(t, util.EUR, 10)
+	cash := houseAccount(t, util.USD, db.AccountKindAsset)
+
+	testCases := []struct {
+		name     string
+		postings []db.Posting
+		err      error
+	}{
+		{
+			name:     "SinglePosting",
+			postings: []db.Posting{{AccountID: usdAcc.ID, Amount: 10}},
+			err:      db.ErrInvalidJournal,
+		},
+		{
+			name:     "ZeroPosting",
+			postings: []db.Posting{{AccountID: usdAcc.ID, Amount: 0}, {AccountID: cash.ID, Amount: 0}},
+			err:      db.ErrInvalidJournal,
+		},
+		{
+			name:     "Unbalanced",
+			postings: []db.Posting{{AccountID: usdAcc.ID, Amount: 10}, {AccountID: cash.ID, Amount: -9}},
+			err:      db.ErrUnbalancedJournal,
+		},
+		{
+			// the sum is zero, but not within each currency
+			name:     "AcrossCurrencies",
+			postings: []db.Posting{{AccountID: usdAcc.ID, Amount: -5}, {AccountID: eurAcc.ID, Amount: 5}},
+			err:      db.ErrUnbalancedJournal,
+		},
+		{
+			name:     "InsufficientFunds",
+			postings: []db.Posting{{AccountID: usdAcc.ID, Amount: -11}, {AccountID: cash.ID, Amount: 11}},
+			err:      db.ErrInsufficientFunds,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.PostJournal(context.Background(), tc.postings)
+			require.ErrorIs(t, err, tc.err)
+
+			updated, err := testQueries.GetAccount(context.Background(), usdAcc.ID)
+			require.NoError(t, err)
+			require.Equal(t, usdAcc.Balance, updated.Balance)
+		})
+	}
+}
+
+func TestCrossCurrencyTransferPostsThroughHouse(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createCurrencyAccount(t, util.USD, 1000)
+	acc2 := createCurrencyAccount(t, util.EUR, 0)
+	usdHouse := houseAccount(t, util.USD, db.AccountKindAsset)
+	eurHouse := houseAccount(t, util.EUR, db.AccountKindAsset)
+
+	res, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        100,
+		ToAmount:      92,
+		FxRate:        92_000_000,
+	})
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListTransferEntries(context.Background(), sql.NullInt64{Int64: res.Transfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	totals := make(map[int64]int64)
+	for _, entry := range entries {
+		totals[entry.AccountID] += entry.Amount
+	}
+	require.Equal(t, map[int64]int64{
+		acc1.ID:     -100,
+		acc2.ID:     92,
+		usdHouse.ID: 100,
+		eurHouse.ID: -92,
+	}, totals)
+}
@@ -1,9 +1,11 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"os"
+	"simplebank/currency"
 	db "simplebank/db/sqlc"
 	"simplebank/util"
 	"testing"
@@ -29,5 +31,10 @@ func TestMain(m *testing.M) {
 
 	testQueries = db.New(testDB)
 
+	// cross-currency transfers go through the house accounts
+	if _, err := db.NewStore(testDB).SeedHouseAccountsTx(context.Background(), currency.Default().Codes()); err != nil {
+		log.Fatal("cannot seed house accounts: ", err)
+	}
+
 	os.Exit(m.Run())
 }
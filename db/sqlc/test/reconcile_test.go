@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileLedger(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	// funded accounts get their balance without an entry
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+
+	_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+
+	// a fee as large as the amount debits the sender twice the same amount
+	createFeeSchedule(t, acc1, db.UpsertFeeScheduleParams{FlatFee: 10})
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// a transfer recorded without its entries
+	bare, err := testQueries.CreateTransfer(context.Background(), db.CreateTransferParams{
+		FromAccountID: acc2.ID,
+		ToAccountID:   acc1.ID,
+		Amount:        5,
+		ToAmount:      5,
+		FxRate:        util.FXRateScale,
+	})
+	require.NoError(t, err)
+
+	res, err := store.ReconcileLedger(context.Background(), db.ReconcileLedgerParams{ChunkSize: 7})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, res.AccountsScanned, int64(2))
+
+	var found []db.LedgerDiscrepancy
+	for _, discrepancy := range res.Discrepancies {
+		if discrepancy.AccountID == acc1.ID || discrepancy.AccountID == acc2.ID {
+			found = append(found, discrepancy)
+		}
+	}
+
+	require.ElementsMatch(t, []db.LedgerDiscrepancy{
+		{Kind: db.DiscrepancyBalance, AccountID: acc1.ID, Expected: -50, Actual: 50},
+		{Kind: db.DiscrepancyDebitEntries, AccountID: acc2.ID, TransferID: bare.ID, Expected: 1, Actual: 0},
+		{Kind: db.DiscrepancyCreditEntries, AccountID: acc1.ID, TransferID: bare.ID, Expected: 1, Actual: 0},
+	}, found)
+}
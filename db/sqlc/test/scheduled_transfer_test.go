@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// dueTime returns a time earlier than any schedule left over by previous test runs,
+// so ExecuteScheduledTransferTx only picks up the schedules of the current test
+func dueTime() time.Time {
+	return time.Unix(0, -time.Now().UnixNano()).Truncate(time.Microsecond)
+}
+
+func createScheduledTransfer(t *testing.T, from db.Account, to db.Account, recurrence string, dayOfMonth int32, scheduledFor time.Time) db.ScheduledTransfer {
+	arg := db.CreateScheduledTransferParams{
+		Owner:         from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Recurrence:    recurrence,
+		DayOfMonth:    sql.NullInt32{Int32: dayOfMonth, Valid: dayOfMonth != 0},
+		ScheduledFor:  scheduledFor,
+	}
+
+	schedule, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, db.ScheduleStatusActive, schedule.Status)
+	require.WithinDuration(t, scheduledFor, schedule.NextRunAt, time.Microsecond)
+	require.Zero(t, schedule.Attempts)
+
+	return schedule
+}
+
+func TestExecuteScheduledTransferTx(t *testing.T) {
+	store := db.NewStore(testDB)
+	now := dueTime()
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+	schedule := createScheduledTransfer(t, acc1, acc2, db.RecurrenceOnce, 0, now)
+
+	res, err := store.ExecuteScheduledTransferTx(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, schedule.ID, res.ScheduledTransfer.ID)
+	require.Equal(t, db.ScheduleStatusCompleted, res.ScheduledTransfer.Status)
+	require.Equal(t, db.RunStatusSucceeded, res.Run.Status)
+	require.Equal(t, int32(1), res.Run.Attempt)
+	require.True(t, res.Run.TransferID.Valid)
+
+	transfer, err := testQueries.GetTransfer(context.Background(), res.Run.TransferID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, acc1.ID, transfer.FromAccountID)
+	require.Equal(t, acc2.ID, transfer.ToAccountID)
+	require.Equal(t, schedule.Amount, transfer.Amount)
+
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), now)
+	require.ErrorIs(t, err, db.ErrNoScheduledTransferDue)
+}
+
+func TestExecuteScheduledTransferTxRetriesInsufficientFunds(t *testing.T) {
+	store := db.NewStore(testDB)
+	now := dueTime()
+
+	acc1 := createFundedAccount(t, 5)
+	acc2 := createFundedAccount(t, 0)
+	schedule := createScheduledTransfer(t, acc1, acc2, db.RecurrenceMonthly, 31, now)
+
+	res, err := store.ExecuteScheduledTransferTx(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, db.RunStatusFailed, res.Run.Status)
+	require.False(t, res.Run.TransferID.Valid)
+	require.True(t, res.Run.Error.Valid)
+
+	retried := res.ScheduledTransfer
+	require.Equal(t, db.ScheduleStatusActive, retried.Status)
+	require.Equal(t, int32(1), retried.Attempts)
+	require.WithinDuration(t, schedule.ScheduledFor, retried.ScheduledFor, time.Microsecond)
+	require.WithinDuration(t, now.Add(db.ScheduleRetryBackoff(1)), retried.NextRunAt, time.Microsecond)
+
+	// the retry isn't due yet
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), now)
+	require.ErrorIs(t, err, db.ErrNoScheduledTransferDue)
+
+	paused, err := store.ChangeScheduledTransferStatusTx(context.Background(), db.ChangeScheduledTransferStatusTxParams{
+		ID:     schedule.ID,
+		Status: db.ScheduleStatusPaused,
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.ScheduleStatusPaused, paused.Status)
+
+	// resuming skips the occurrences missed while paused
+	resumed, err := store.ChangeScheduledTransferStatusTx(context.Background(), db.ChangeScheduledTransferStatusTxParams{
+		ID:     schedule.ID,
+		Status: db.ScheduleStatusActive,
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.ScheduleStatusActive, resumed.Status)
+	require.Zero(t, resumed.Attempts)
+	require.True(t, resumed.NextRunAt.After(time.Now()))
+
+	cancelled, err := store.ChangeScheduledTransferStatusTx(context.Background(), db.ChangeScheduledTransferStatusTxParams{
+		ID:     schedule.ID,
+		Status: db.ScheduleStatusCancelled,
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.ScheduleStatusCancelled, cancelled.Status)
+
+	_, err = store.ChangeScheduledTransferStatusTx(context.Background(), db.ChangeScheduledTransferStatusTxParams{
+		ID:     schedule.ID,
+		Status: db.ScheduleStatusActive,
+	})
+	require.ErrorIs(t, err, db.ErrInvalidStatusTransition)
+
+	acc1, err = testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), acc1.Balance)
+}
+
+func TestExecuteScheduledTransferTxGivesUpOnOtherErrors(t *testing.T) {
+	store := db.NewStore(testDB)
+	now := dueTime()
+
+	acc1 := createCurrencyAccount(t, util.USD, 100)
+	acc2 := createCurrencyAccount(t, util.USD, 0)
+
+	// the fee is in dollars but its house account holds euros
+	feeAcc := createCurrencyAccount(t, util.EUR, 0)
+	tier := util.RandomString(8)
+	_, err := testQueries.UpdateAccountTier(context.Background(), db.UpdateAccountTierParams{
+		ID:   acc1.ID,
+		Tier: tier,
+	})
+	require.NoError(t, err)
+	_, err = testQueries.UpsertFeeSchedule(context.Background(), db.UpsertFeeScheduleParams{
+		Currency:     acc1.Currency,
+		Tier:         tier,
+		FlatFee:      1,
+		FeeAccountID: feeAcc.ID,
+	})
+	require.NoError(t, err)
+
+	schedule := createScheduledTransfer(t, acc1, acc2, db.RecurrenceOnce, 0, now)
+
+	res, err := store.ExecuteScheduledTransferTx(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, schedule.ID, res.ScheduledTransfer.ID)
+	require.Equal(t, db.ScheduleStatusFailed, res.ScheduledTransfer.Status)
+	require.Equal(t, db.RunStatusFailed, res.Run.Status)
+	require.False(t, res.Run.TransferID.Valid)
+	require.Contains(t, res.Run.Error.String, db.ErrFeeAccountMismatch.Error())
+
+	// the failed schedule no longer holds up the queue
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), now)
+	require.ErrorIs(t, err, db.ErrNoScheduledTransferDue)
+
+	acc1, err = testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), acc1.Balance)
+}
+
+func TestNextOccurrence(t *testing.T) {
+	jan31 := time.Date(2024, time.January, 31, 9, 30, 0, 0, time.UTC)
+
+	feb29 := db.NextOccurrence(db.RecurrenceMonthly, 31, jan31)
+	require.Equal(t, time.Date(2024, time.February, 29, 9, 30, 0, 0, time.UTC), feb29)
+
+	mar31 := db.NextOccurrence(db.RecurrenceMonthly, 31, feb29)
+	require.Equal(t, time.Date(2024, time.March, 31, 9, 30, 0, 0, time.UTC), mar31)
+
+	dec15 := time.Date(2024, time.December, 15, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC), db.NextOccurrence(db.RecurrenceMonthly, 15, dec15))
+
+	require.Equal(t, jan31.AddDate(0, 0, 1), db.NextOccurrence(db.RecurrenceDaily, 0, jan31))
+	require.Equal(t, jan31.AddDate(0, 0, 7), db.NextOccurrence(db.RecurrenceWeekly, 0, jan31))
+
+	require.Equal(t, time.Date(2024, time.February, 10, 9, 30, 0, 0, time.UTC), db.FirstMonthlyOccurrence(jan31, 10))
+	require.Equal(t, jan31, db.FirstMonthlyOccurrence(jan31, 31))
+}
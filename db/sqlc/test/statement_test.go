@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountStatementTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	from := time.Now().Add(-time.Minute)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+	house, _ := createFeeSchedule(t, acc1, db.UpsertFeeScheduleParams{FlatFee: 2})
+
+	res1, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc2.ID,
+		ToAccountID:   acc1.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// every entry of a transfer, the fee pair included, points back to it
+	entries, err := testQueries.ListTransferEntries(context.Background(), sql.NullInt64{Int64: res1.Transfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.Equal(t, res1.FromEntry.ID, entries[0].ID)
+
+	statement, err := store.AccountStatementTx(context.Background(), db.AccountStatementTxParams{
+		AccountID: acc1.ID,
+		From:      from,
+		To:        time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	// the funded balance has no entry, statements only add up entries
+	require.Zero(t, statement.OpeningBalance)
+	require.Equal(t, int64(-22), statement.ClosingBalance)
+	require.Len(t, statement.Entries, 3)
+
+	expected := []struct {
+		amount         int64
+		counterparty   int64
+		runningBalance int64
+	}{
+		{-30, acc2.ID, -30},
+		{-2, house.ID, -32},
+		{10, acc2.ID, -22},
+	}
+
+	for i, entry := range statement.Entries {
+		require.Equal(t, expected[i].amount, entry.Amount)
+		require.Equal(t, expected[i].counterparty, entry.CounterpartyAccountID.Int64)
+		require.Equal(t, expected[i].runningBalance, entry.RunningBalance)
+		require.True(t, entry.TransferID.Valid)
+	}
+
+	// a later period opens with the closing balance of this one
+	statement, err = store.AccountStatementTx(context.Background(), db.AccountStatementTxParams{
+		AccountID: acc1.ID,
+		From:      time.Now().Add(time.Minute),
+		To:        time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-22), statement.OpeningBalance)
+	require.Equal(t, int64(-22), statement.ClosingBalance)
+	require.Empty(t, statement.Entries)
+}
+
+// collectedStatement is a StatementWriter that keeps what it is given
+type collectedStatement struct {
+	db.AccountStatement
+	headers int
+}
+
+func (c *collectedStatement) WriteHeader(statement db.AccountStatement) error {
+	c.AccountStatement = statement
+	c.headers++
+	return nil
+}
+
+func (c *collectedStatement) WriteEntry(entry db.ListStatementEntriesRow) error {
+	c.Entries = append(c.Entries, entry)
+	return nil
+}
+
+func TestStreamAccountStatementTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	from := time.Now().Add(-time.Minute)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+
+	for i := 0; i < 3; i++ {
+		_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: acc1.ID,
+			ToAccountID:   acc2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+	}
+
+	arg := db.AccountStatementTxParams{
+		AccountID: acc1.ID,
+		From:      from,
+		To:        time.Now().Add(time.Minute),
+	}
+
+	expected, err := store.AccountStatementTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	var streamed collectedStatement
+	err = store.StreamAccountStatementTx(context.Background(), arg, &streamed)
+	require.NoError(t, err)
+	require.Equal(t, 1, streamed.headers)
+	require.Equal(t, expected, streamed.AccountStatement)
+
+	// an error of the writer stops the stream and is returned
+	errWrite := errors.New("client went away")
+	failing := &failingStatementWriter{err: errWrite}
+	err = store.StreamAccountStatementTx(context.Background(), arg, failing)
+	require.ErrorIs(t, err, errWrite)
+	require.Equal(t, 1, failing.entries)
+}
+
+type failingStatementWriter struct {
+	err     error
+	entries int
+}
+
+func (f *failingStatementWriter) WriteHeader(db.AccountStatement) error {
+	return nil
+}
+
+func (f *failingStatementWriter) WriteEntry(db.ListStatementEntriesRow) error {
+	f.entries++
+	return f.err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	db "simplebank/db/sqlc"
+	"simplebank/util"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -12,13 +13,14 @@ import (
 func TestTransferTx(t *testing.T) {
 	store := db.NewStore(testDB)
 
-	acc1 := createRandomAccount(t)
+	n := 6
+	amount := int64(40)
+
+	acc1 := createFundedAccount(t, int64(n)*amount)
 	acc2 := createRandomAccount(t)
 
 	fmt.Println(">> before", acc1.Balance, acc2.Balance)
 
-	n := 6
-	amount := int64(40)
 	errs := make(chan error)
 	results := make(chan db.TransferTxResult)
 
@@ -107,13 +109,14 @@ func TestTransferTx(t *testing.T) {
 func TestTransferTxDeadlock(t *testing.T) {
 	store := db.NewStore(testDB)
 
-	acc1 := createRandomAccount(t)
-	acc2 := createRandomAccount(t)
+	n := 10
+	amount := int64(40)
+
+	acc1 := createFundedAccount(t, int64(n)*amount)
+	acc2 := createFundedAccount(t, int64(n)*amount)
 
 	fmt.Println(">> before", acc1.Balance, acc2.Balance)
 
-	n := 10
-	amount := int64(40)
 	errs := make(chan error)
 
 	for i := 0; i < n; i++ {
@@ -149,3 +152,228 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, acc1.Balance, updatedAcc1.Balance)
 	require.Equal(t, acc2.Balance, updatedAcc2.Balance)
 }
+
+func TestIdempotentTransferTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 10)
+	acc2 := createRandomAccount(t)
+
+	arg := db.IdempotentTransferTxParams{
+		TransferTxParams: db.TransferTxParams{
+			FromAccountID: acc1.ID,
+			ToAccountID:   acc2.ID,
+			Amount:        10,
+		},
+		Username:       acc1.Owner,
+		IdempotencyKey: util.RandomString(16),
+		RequestHash:    util.RandomString(32),
+	}
+
+	res1, err := store.IdempotentTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, res1.Replayed)
+	require.NotZero(t, res1.Transfer.ID)
+
+	// the retry must not move money again
+	res2, err := store.IdempotentTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, res2.Replayed)
+	require.Equal(t, res1.Transfer.ID, res2.Transfer.ID)
+	require.Equal(t, res1.FromAccount.Balance, res2.FromAccount.Balance)
+
+	updatedAcc1, err := testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Balance-arg.Amount, updatedAcc1.Balance)
+
+	arg.Amount = 5
+	arg.RequestHash = util.RandomString(32)
+	_, err = store.IdempotentTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, db.ErrIdempotencyKeyReused)
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	n := 10
+	amount := int64(40)
+	fits := 3
+
+	acc1 := createFundedAccount(t, int64(fits)*amount+amount/2)
+	acc2 := createRandomAccount(t)
+
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+				FromAccountID: acc1.ID,
+				ToAccountID:   acc2.ID,
+				Amount:        amount,
+			})
+
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+
+		var fundsErr *db.InsufficientFundsError
+		require.ErrorAs(t, err, &fundsErr)
+		require.ErrorIs(t, err, db.ErrInsufficientFunds)
+		require.Equal(t, acc1.ID, fundsErr.AccountID)
+		require.Equal(t, amount, fundsErr.Requested)
+	}
+
+	require.Equal(t, fits, succeeded)
+
+	updatedAcc1, err := testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Balance-int64(fits)*amount, updatedAcc1.Balance)
+	require.True(t, updatedAcc1.Balance >= 0)
+}
+
+// createFundedAccount creates a random account holding exactly balance
+func createFundedAccount(t *testing.T, balance int64) db.Account {
+	acc := createRandomAccount(t)
+
+	acc, err := testQueries.UpdateAccount(context.Background(), db.UpdateAccountParams{
+		ID:      acc.ID,
+		Balance: balance,
+	})
+	require.NoError(t, err)
+
+	return acc
+}
+
+// createCurrencyAccount creates an account in currency with balance, for transfers that must cross currencies
+func createCurrencyAccount(t *testing.T, currency string, balance int64) db.Account {
+	user := createRandomUser(t)
+
+	acc, err := testQueries.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+
+	return acc
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+
+	original, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	// partial refund
+	res, err := store.ReverseTransferTx(context.Background(), db.ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     30,
+	})
+	require.NoError(t, err)
+	require.Equal(t, acc2.ID, res.Transfer.FromAccountID)
+	require.Equal(t, acc1.ID, res.Transfer.ToAccountID)
+	require.Equal(t, int64(30), res.Transfer.Amount)
+	require.Equal(t, original.Transfer.ID, res.Transfer.ReversalOf.Int64)
+	require.True(t, res.Transfer.ReversalOf.Valid)
+	require.Equal(t, int64(70), res.RemainingAmount)
+	require.Equal(t, int64(-30), res.FromEntry.Amount)
+	require.Equal(t, int64(30), res.ToEntry.Amount)
+
+	// more than what is left
+	_, err = store.ReverseTransferTx(context.Background(), db.ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     71,
+	})
+	require.ErrorIs(t, err, db.ErrRefundExceedsRemaining)
+
+	// a reversal itself can't be reversed
+	_, err = store.ReverseTransferTx(context.Background(), db.ReverseTransferTxParams{
+		TransferID: res.Transfer.ID,
+	})
+	require.ErrorIs(t, err, db.ErrTransferIsReversal)
+
+	// refund the rest
+	res, err = store.ReverseTransferTx(context.Background(), db.ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), res.Transfer.Amount)
+	require.Zero(t, res.RemainingAmount)
+
+	_, err = store.ReverseTransferTx(context.Background(), db.ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.ErrorIs(t, err, db.ErrRefundExceedsRemaining)
+
+	updatedAcc1, err := testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Balance, updatedAcc1.Balance)
+
+	updatedAcc2, err := testQueries.GetAccount(context.Background(), acc2.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc2.Balance, updatedAcc2.Balance)
+}
+
+func TestCrossCurrencyTransferTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createCurrencyAccount(t, util.USD, 1000)
+	acc2 := createCurrencyAccount(t, util.EUR, 0)
+
+	res, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        100,
+		ToAmount:      92,
+		FxRate:        92_000_000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), res.Transfer.Amount)
+	require.Equal(t, int64(92), res.Transfer.ToAmount)
+	require.Equal(t, int64(92_000_000), res.Transfer.FxRate)
+	require.Equal(t, int64(-100), res.FromEntry.Amount)
+	require.Equal(t, int64(92), res.ToEntry.Amount)
+	require.Equal(t, acc1.Balance-100, res.FromAccount.Balance)
+	require.Equal(t, acc2.Balance+92, res.ToAccount.Balance)
+
+	// a partial refund of 50 in the sender's currency takes back 46 at the original rate
+	rev, err := store.ReverseTransferTx(context.Background(), db.ReverseTransferTxParams{
+		TransferID: res.Transfer.ID,
+		Amount:     50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(46), rev.Transfer.Amount)
+	require.Equal(t, int64(50), rev.Transfer.ToAmount)
+	require.Equal(t, int64(50), rev.RemainingAmount)
+}
+
+func TestSameCurrencyTransferTxDefaultsDestinationLeg(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createRandomAccount(t)
+
+	res, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, res.Transfer.Amount, res.Transfer.ToAmount)
+	require.Equal(t, util.FXRateScale, res.Transfer.FxRate)
+}
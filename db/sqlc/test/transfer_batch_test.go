@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	db "simplebank/db/sqlc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferBatchTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	payer := createFundedAccount(t, 100)
+	payees := []db.Account{createFundedAccount(t, 0), createFundedAccount(t, 0), createFundedAccount(t, 0)}
+
+	legs := make([]db.TransferTxParams, len(payees))
+	for i, payee := range payees {
+		legs[i] = db.TransferTxParams{
+			FromAccountID: payer.ID,
+			ToAccountID:   payee.ID,
+			Amount:        int64(10 * (i + 1)),
+		}
+	}
+
+	res, err := store.TransferBatchTx(context.Background(), db.TransferBatchTxParams{
+		Owner: payer.Owner,
+		Legs:  legs,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, res.Batch.ID)
+	require.Len(t, res.Legs, len(legs))
+
+	for i, leg := range res.Legs {
+		require.Equal(t, res.Batch.ID, leg.Transfer.BatchID.Int64)
+		require.Equal(t, legs[i].Amount, leg.Transfer.Amount)
+		require.Equal(t, legs[i].Amount, leg.ToAccount.Balance)
+	}
+
+	// every leg sees the balance left by the previous ones
+	require.Equal(t, int64(40), res.Legs[len(legs)-1].FromAccount.Balance)
+
+	transfers, err := testQueries.ListBatchTransfers(context.Background(), res.Legs[0].Transfer.BatchID)
+	require.NoError(t, err)
+	require.Len(t, transfers, len(legs))
+}
+
+func TestTransferBatchTxIsAtomic(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	payer := createFundedAccount(t, 50)
+	payee1 := createFundedAccount(t, 0)
+	payee2 := createFundedAccount(t, 0)
+
+	_, err := store.TransferBatchTx(context.Background(), db.TransferBatchTxParams{
+		Owner: payer.Owner,
+		Legs: []db.TransferTxParams{
+			{FromAccountID: payer.ID, ToAccountID: payee1.ID, Amount: 30},
+			{FromAccountID: payer.ID, ToAccountID: payee2.ID, Amount: 30},
+		},
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	var legErr *db.BatchLegError
+	require.ErrorAs(t, err, &legErr)
+	require.Equal(t, 1, legErr.Index)
+
+	// the first leg was rolled back with the second
+	payer, err = testQueries.GetAccount(context.Background(), payer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), payer.Balance)
+
+	payee1, err = testQueries.GetAccount(context.Background(), payee1.ID)
+	require.NoError(t, err)
+	require.Zero(t, payee1.Balance)
+}
+
+func TestTransferBatchTxDeadlock(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 1000)
+	acc2 := createFundedAccount(t, 1000)
+	acc3 := createFundedAccount(t, 1000)
+
+	n := 10
+	errs := make(chan error)
+
+	// batches crossing the same accounts in opposite directions
+	for i := 0; i < n; i++ {
+		legs := []db.TransferTxParams{
+			{FromAccountID: acc3.ID, ToAccountID: acc1.ID, Amount: 10},
+			{FromAccountID: acc2.ID, ToAccountID: acc3.ID, Amount: 10},
+		}
+		if i%2 == 1 {
+			legs = []db.TransferTxParams{
+				{FromAccountID: acc1.ID, ToAccountID: acc2.ID, Amount: 10},
+				{FromAccountID: acc1.ID, ToAccountID: acc3.ID, Amount: 10},
+			}
+		}
+
+		go func() {
+			_, err := store.TransferBatchTx(context.Background(), db.TransferBatchTxParams{
+				Owner: acc1.Owner,
+				Legs:  legs,
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// acc1 got 10 from each of the n/2 first batches and paid 20 in each of the others
+	acc1, err := testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000+10*n/2-20*n/2), acc1.Balance)
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxDailyCountLimit(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	n := 10
+	amount := int64(10)
+	limit := 3
+
+	acc1 := createFundedAccount(t, int64(n)*amount)
+	acc2 := createFundedAccount(t, 0)
+
+	_, err := testQueries.UpsertTransferLimit(context.Background(), db.UpsertTransferLimitParams{
+		AccountID:  sql.NullInt64{Int64: acc1.ID, Valid: true},
+		DailyCount: sql.NullInt64{Int64: int64(limit), Valid: true},
+	})
+	require.NoError(t, err)
+
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+				FromAccountID: acc1.ID,
+				ToAccountID:   acc2.ID,
+				Amount:        amount,
+			})
+
+			errs <- err
+		}()
+	}
+
+	now := time.Now().UTC()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+
+		var limitErr *db.TransferLimitError
+		require.ErrorAs(t, err, &limitErr)
+		require.ErrorIs(t, err, db.ErrTransferLimitExceeded)
+		require.Equal(t, db.LimitScopeAccount, limitErr.Scope)
+		require.Equal(t, db.LimitPeriodDaily, limitErr.Period)
+		require.Equal(t, db.LimitKindCount, limitErr.Kind)
+		require.Equal(t, int64(limit), limitErr.Limit)
+		require.True(t, tomorrow.Equal(limitErr.ResetsAt))
+	}
+
+	require.Equal(t, limit, succeeded)
+
+	updatedAcc1, err := testQueries.GetAccount(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Balance-int64(limit)*amount, updatedAcc1.Balance)
+}
+
+func TestTransferTxOwnerAmountLimit(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2, err := testQueries.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner:    acc1.Owner,
+		Balance:  100,
+		Currency: acc1.Currency,
+	})
+	require.NoError(t, err)
+	payee := createFundedAccount(t, 0)
+
+	_, err = testQueries.UpsertTransferLimit(context.Background(), db.UpsertTransferLimitParams{
+		Owner:         sql.NullString{String: acc1.Owner, Valid: true},
+		MonthlyAmount: sql.NullInt64{Int64: 100, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   payee.ID,
+		Amount:        60,
+	})
+	require.NoError(t, err)
+
+	// the owner's limit adds up the transfers of all their accounts
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc2.ID,
+		ToAccountID:   payee.ID,
+		Amount:        60,
+	})
+
+	var limitErr *db.TransferLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, db.LimitScopeUser, limitErr.Scope)
+	require.Equal(t, db.LimitPeriodMonthly, limitErr.Period)
+	require.Equal(t, db.LimitKindAmount, limitErr.Kind)
+	require.Equal(t, int64(60), limitErr.Used)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc2.ID,
+		ToAccountID:   payee.ID,
+		Amount:        40,
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createWebhook(t *testing.T, owner string, eventTypes ...string) db.Webhook {
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	webhook, err := testQueries.CreateWebhook(context.Background(), db.CreateWebhookParams{
+		Owner:      owner,
+		Url:        "http://localhost:8081/hooks",
+		Secret:     "whsec_test",
+		EventTypes: eventTypes,
+	})
+	require.NoError(t, err)
+	require.Equal(t, eventTypes, webhook.EventTypes)
+
+	return webhook
+}
+
+// dispatchOutbox fans out every pending event of the outbox
+func dispatchOutbox(t *testing.T, store db.Store) {
+	for {
+		n, err := store.DispatchOutboxTx(context.Background(), db.DefaultOutboxBatchSize)
+		require.NoError(t, err)
+
+		if n < db.DefaultOutboxBatchSize {
+			return
+		}
+	}
+}
+
+func listDeliveries(t *testing.T, webhookID int64, status string) []db.WebhookDelivery {
+	deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), db.ListWebhookDeliveriesParams{
+		WebhookID:   webhookID,
+		Status:      status,
+		LimitCount:  10,
+		OffsetCount: 0,
+	})
+	require.NoError(t, err)
+
+	return deliveries
+}
+
+func TestCreateAccountTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	user := createRandomUser(t)
+	webhook := createWebhook(t, user.Username)
+
+	acc, err := store.CreateAccountTx(context.Background(), db.CreateAccountParams{
+		Owner:    user.Username,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	dispatchOutbox(t, store)
+
+	deliveries := listDeliveries(t, webhook.ID, "")
+	require.Len(t, deliveries, 1)
+	require.Equal(t, db.WebhookDeliveryPending, deliveries[0].Status)
+
+	event, err := testQueries.GetOutboxEvent(context.Background(), deliveries[0].EventID)
+	require.NoError(t, err)
+	require.Equal(t, db.EventAccountCreated, event.EventType)
+	require.Equal(t, []string{user.Username}, event.Owners)
+	require.True(t, event.DispatchedAt.Valid)
+
+	var payload db.Account
+	require.NoError(t, json.Unmarshal(event.Payload, &payload))
+	require.Equal(t, acc.ID, payload.ID)
+}
+
+func TestDispatchOutboxTx(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	acc1 := createFundedAccount(t, 100)
+	acc2 := createFundedAccount(t, 0)
+
+	webhook1 := createWebhook(t, acc1.Owner)
+	webhook2 := createWebhook(t, acc2.Owner, db.EventTransferCreated)
+	// a webhook of another user hears nothing
+	webhook3 := createWebhook(t, createRandomUser(t).Username)
+
+	res, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: acc1.ID,
+		ToAccountID:   acc2.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+
+	dispatchOutbox(t, store)
+
+	// the sender hears the transfer and its own balance change
+	deliveries := listDeliveries(t, webhook1.ID, db.WebhookDeliveryPending)
+	require.Len(t, deliveries, 2)
+
+	events := map[string]db.Outbox{}
+	for _, delivery := range deliveries {
+		event, err := testQueries.GetOutboxEvent(context.Background(), delivery.EventID)
+		require.NoError(t, err)
+		events[event.EventType] = event
+	}
+
+	require.Contains(t, events, db.EventTransferCreated)
+	require.ElementsMatch(t, []string{acc1.Owner, acc2.Owner}, events[db.EventTransferCreated].Owners)
+
+	var change db.AccountBalanceChangedEvent
+	require.NoError(t, json.Unmarshal(events[db.EventAccountBalanceChanged].Payload, &change))
+	require.Equal(t, db.AccountBalanceChangedEvent{
+		AccountID:  acc1.ID,
+		Balance:    70,
+		Change:     -30,
+		TransferID: res.Transfer.ID,
+	}, change)
+
+	// the recipient only subscribed to transfers
+	deliveries = listDeliveries(t, webhook2.ID, "")
+	require.Len(t, deliveries, 1)
+	require.Equal(t, events[db.EventTransferCreated].ID, deliveries[0].EventID)
+
+	require.Empty(t, listDeliveries(t, webhook3.ID, ""))
+
+	// dispatched events aren't fanned out twice
+	dispatchOutbox(t, store)
+	require.Len(t, listDeliveries(t, webhook1.ID, ""), 2)
+}
+
+func TestWebhookDeliveryLifecycle(t *testing.T) {
+	store := db.NewStore(testDB)
+
+	user := createRandomUser(t)
+	webhook := createWebhook(t, user.Username, db.EventAccountCreated)
+
+	_, err := store.CreateAccountTx(context.Background(), db.CreateAccountParams{
+		Owner:    user.Username,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	dispatchOutbox(t, store)
+
+	deliveries := listDeliveries(t, webhook.ID, "")
+	require.Len(t, deliveries, 1)
+	delivery := deliveries[0]
+
+	// other tests leave due deliveries behind, claim until ours comes up
+	now := time.Now()
+	var claimed db.ClaimDueWebhookDeliveryRow
+	for claimed.ID != delivery.ID {
+		claimed, err = testQueries.ClaimDueWebhookDelivery(context.Background(), db.ClaimDueWebhookDeliveryParams{
+			LeaseUntil: now.Add(db.WebhookDeliveryLease),
+			Now:        now,
+		})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(1), claimed.Attempts)
+	require.Equal(t, webhook.Url, claimed.Url)
+	require.Equal(t, webhook.Secret, claimed.Secret)
+	require.Equal(t, db.EventAccountCreated, claimed.EventType)
+
+	// a leased delivery isn't claimed again
+	claimed, err = testQueries.ClaimDueWebhookDelivery(context.Background(), db.ClaimDueWebhookDeliveryParams{
+		LeaseUntil: now.Add(db.WebhookDeliveryLease),
+		Now:        now,
+	})
+	if err != sql.ErrNoRows {
+		require.NoError(t, err)
+		require.NotEqual(t, delivery.ID, claimed.ID)
+	}
+
+	dead, err := testQueries.UpdateWebhookDelivery(context.Background(), db.UpdateWebhookDeliveryParams{
+		ID:            delivery.ID,
+		Status:        db.WebhookDeliveryDead,
+		NextAttemptAt: now,
+		LastError:     sql.NullString{String: "receiver answered 500", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.WebhookDeliveryDead, dead.Status)
+	require.Len(t, listDeliveries(t, webhook.ID, db.WebhookDeliveryDead), 1)
+
+	replayed, err := testQueries.ReplayWebhookDeliveries(context.Background(), db.ReplayWebhookDeliveriesParams{
+		Now:       now,
+		WebhookID: webhook.ID,
+		Ids:       []int64{},
+	})
+	require.NoError(t, err)
+	require.Len(t, replayed, 1)
+	require.Equal(t, db.WebhookDeliveryPending, replayed[0].Status)
+	require.Zero(t, replayed[0].Attempts)
+	require.False(t, replayed[0].LastError.Valid)
+
+	// pending deliveries aren't replayed
+	replayed, err = testQueries.ReplayWebhookDeliveries(context.Background(), db.ReplayWebhookDeliveriesParams{
+		Now:       now,
+		WebhookID: webhook.ID,
+		Ids:       []int64{delivery.ID},
+	})
+	require.NoError(t, err)
+	require.Empty(t, replayed)
+}
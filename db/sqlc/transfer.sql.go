@@ -7,26 +7,49 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id, 
   to_account_id,
-  amount
+  amount,
+  reversal_of,
+  to_amount,
+  fx_rate,
+  batch_id,
+  fee,
+  fee_account_id
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of, to_amount, fx_rate, batch_id, fee, fee_account_id
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	ReversalOf    sql.NullInt64 `json:"reversal_of"`
+	ToAmount      int64         `json:"to_amount"`
+	FxRate        int64         `json:"fx_rate"`
+	BatchID       sql.NullInt64 `json:"batch_id"`
+	Fee           int64         `json:"fee"`
+	FeeAccountID  sql.NullInt64 `json:"fee_account_id"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ReversalOf,
+		arg.ToAmount,
+		arg.FxRate,
+		arg.BatchID,
+		arg.Fee,
+		arg.FeeAccountID,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +57,31 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.BatchID,
+		&i.Fee,
+		&i.FeeAccountID,
 	)
 	return i, err
 }
 
+const getReversedAmount = `-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(to_amount), 0)::bigint AS reversed_amount
+FROM transfers
+WHERE reversal_of = $1
+`
+
+func (q *Queries) GetReversedAmount(ctx context.Context, reversalOf sql.NullInt64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getReversedAmount, reversalOf)
+	var reversed_amount int64
+	err := row.Scan(&reversed_amount)
+	return reversed_amount, err
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, to_amount, fx_rate, batch_id, fee, fee_account_id FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,12 +94,84 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.BatchID,
+		&i.Fee,
+		&i.FeeAccountID,
 	)
 	return i, err
 }
 
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, to_amount, fx_rate, batch_id, fee, fee_account_id FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.BatchID,
+		&i.Fee,
+		&i.FeeAccountID,
+	)
+	return i, err
+}
+
+const listBatchTransfers = `-- name: ListBatchTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, to_amount, fx_rate, batch_id, fee, fee_account_id FROM transfers
+WHERE batch_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListBatchTransfers(ctx context.Context, batchID sql.NullInt64) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listBatchTransfers, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversalOf,
+			&i.ToAmount,
+			&i.FxRate,
+			&i.BatchID,
+			&i.Fee,
+			&i.FeeAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, to_amount, fx_rate, batch_id, fee, fee_account_id FROM transfers
 WHERE 
   from_account_id = $1 AND 
   to_account_id = $2
@@ -93,6 +207,12 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ReversalOf,
+			&i.ToAmount,
+			&i.FxRate,
+			&i.BatchID,
+			&i.Fee,
+			&i.FeeAccountID,
 		); err != nil {
 			return nil, err
 		}
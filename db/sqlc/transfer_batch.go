@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrEmptyBatch is returned when a batch has no legs
+var ErrEmptyBatch = errors.New("batch has no legs")
+
+// BatchLegError is returned when a leg of a batch fails, it wraps the leg's error.
+// The whole batch is rolled back.
+type BatchLegError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchLegError) Error() string {
+	return fmt.Sprintf("batch leg %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchLegError) Unwrap() error {
+	return e.Err
+}
+
+type TransferBatchTxParams struct {
+	Owner string             `json:"owner"`
+	Legs  []TransferTxParams `json:"legs"`
+}
+
+type TransferBatchTxResult struct {
+	Batch TransferBatch      `json:"batch"`
+	Legs  []TransferTxResult `json:"legs"`
+}
+
+// TransferBatchTx runs every leg of a batch in one transaction, either all legs are applied or none.
+// Each leg sees the balances left by the previous ones.
+func (store *SQLStore) TransferBatchTx(ctx context.Context, arg TransferBatchTxParams) (TransferBatchTxResult, error) {
+	var res TransferBatchTxResult
+
+	if len(arg.Legs) == 0 {
+		return res, ErrEmptyBatch
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		ids, err := batchAccountIDs(ctx, q, arg.Legs)
+		if err != nil {
+			return err
+		}
+
+		// lock every account of the batch up front in id order, the legs then only touch rows
+		// this transaction already holds, so the batch can't deadlock with other transfers
+		if _, err := lockAccountsInOrder(ctx, q, ids...); err != nil {
+			return err
+		}
+
+		res.Batch, err = q.CreateTransferBatch(ctx, arg.Owner)
+		if err != nil {
+			return err
+		}
+
+		batchID := sql.NullInt64{Int64: res.Batch.ID, Valid: true}
+		res.Legs = make([]TransferTxResult, 0, len(arg.Legs))
+
+		for i, leg := range arg.Legs {
+			leg.BatchID = batchID
+
+			legRes, err := transfer(ctx, q, leg)
+			if err != nil {
+				return &BatchLegError{Index: i, Err: err}
+			}
+			res.Legs = append(res.Legs, legRes)
+		}
+
+		return recordAudit(ctx, q, AuditTransferBatchCreate, res.Batch.ID, nil, res.Batch)
+	})
+
+	return res, err
+}
+
+// batchAccountIDs returns the accounts of the legs, including the house accounts their fees
+// and currency conversions go to
+func batchAccountIDs(ctx context.Context, q *Queries, legs []TransferTxParams) ([]int64, error) {
+	ids := make([]int64, 0, 2*len(legs)+1)
+
+	for _, leg := range legs {
+		plan, err := planTransfer(ctx, q, leg)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, plan.accountIDs(leg)...)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,197 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Scopes of a transfer limit, account limits fall back to the global defaults
+const (
+	LimitScopeAccount = "account"
+	LimitScopeUser    = "user"
+)
+
+// Periods of a transfer limit, they follow the UTC calendar
+const (
+	LimitPeriodDaily   = "daily"
+	LimitPeriodMonthly = "monthly"
+)
+
+// What a transfer limit caps
+const (
+	LimitKindCount  = "count"
+	LimitKindAmount = "amount"
+)
+
+// ErrTransferLimitExceeded is matched by every TransferLimitError
+var ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+
+// TransferLimitError is returned when a transfer would take the outgoing transfers of an
+// account or a user over one of its limits
+type TransferLimitError struct {
+	Scope  string
+	Period string
+	Kind   string
+	Limit  int64
+	// Used is the count or amount already sent in the period
+	Used     int64
+	ResetsAt time.Time
+}
+
+func (e *TransferLimitError) Error() string {
+	return fmt.Sprintf("%s %s %s limit of %d exceeded, %d used, resets at %s",
+		e.Scope, e.Period, e.Kind, e.Limit, e.Used, e.ResetsAt.Format(time.RFC3339))
+}
+
+func (e *TransferLimitError) Is(target error) bool {
+	return target == ErrTransferLimitExceeded
+}
+
+// transferTotals is what an account or a user sent since the start of a period
+type transferTotals struct {
+	Count  int64
+	Amount int64
+}
+
+// limitPeriod is a calendar period of the limits, from its start up to when it resets
+type limitPeriod struct {
+	Name     string
+	Start    time.Time
+	ResetsAt time.Time
+	Count    sql.NullInt64
+	Amount   sql.NullInt64
+}
+
+// checkTransferLimits makes sure a debit of amount from fromAcc stays within the daily and monthly
+// limits of the account and of its owner. The account must already be locked, so the totals
+// can't race with concurrent transfers. Owner limits lock the user row for the same reason,
+// always after the accounts.
+func checkTransferLimits(ctx context.Context, q *Queries, fromAcc Account, amount int64, now time.Time) error {
+	rows, err := q.ListApplicableTransferLimits(ctx, ListApplicableTransferLimitsParams{
+		AccountID: fromAcc.ID,
+		Owner:     fromAcc.Owner,
+	})
+	if err != nil {
+		return err
+	}
+
+	var defaults, accountLimits, ownerLimits TransferLimit
+	var hasOwnerLimits bool
+
+	for _, row := range rows {
+		switch {
+		case row.AccountID.Valid:
+			accountLimits = row
+		case row.Owner.Valid:
+			ownerLimits = row
+			hasOwnerLimits = true
+		default:
+			defaults = row
+		}
+	}
+
+	// the account's own limits override the global defaults one by one
+	accountLimits = mergeTransferLimits(defaults, accountLimits)
+
+	err = checkLimitPeriods(LimitScopeAccount, accountLimits, amount, now, func(since time.Time) (transferTotals, error) {
+		totals, err := q.GetAccountTransferTotals(ctx, GetAccountTransferTotalsParams{
+			AccountID: fromAcc.ID,
+			Since:     since,
+		})
+		return transferTotals(totals), err
+	})
+	if err != nil || !hasOwnerLimits {
+		return err
+	}
+
+	// transfers from the owner's other accounts don't lock this account, the user row serializes them
+	if _, err := q.GetUserForUpdate(ctx, fromAcc.Owner); err != nil {
+		return err
+	}
+
+	// owner amounts only add up transfers in the currency of this one
+	return checkLimitPeriods(LimitScopeUser, ownerLimits, amount, now, func(since time.Time) (transferTotals, error) {
+		totals, err := q.GetOwnerTransferTotals(ctx, GetOwnerTransferTotalsParams{
+			Currency: fromAcc.Currency,
+			Owner:    fromAcc.Owner,
+			Since:    since,
+		})
+		return transferTotals(totals), err
+	})
+}
+
+// checkLimitPeriods checks one scope of limits, totals are only queried for the periods that are limited
+func checkLimitPeriods(scope string, limits TransferLimit, amount int64, now time.Time, totals func(since time.Time) (transferTotals, error)) error {
+	for _, period := range limitPeriods(limits, now) {
+		if !period.Count.Valid && !period.Amount.Valid {
+			continue
+		}
+
+		used, err := totals(period.Start)
+		if err != nil {
+			return err
+		}
+
+		limitErr := &TransferLimitError{
+			Scope:    scope,
+			Period:   period.Name,
+			ResetsAt: period.ResetsAt,
+		}
+
+		switch {
+		case period.Count.Valid && used.Count+1 > period.Count.Int64:
+			limitErr.Kind = LimitKindCount
+			limitErr.Limit = period.Count.Int64
+			limitErr.Used = used.Count
+			return limitErr
+		case period.Amount.Valid && used.Amount+amount > period.Amount.Int64:
+			limitErr.Kind = LimitKindAmount
+			limitErr.Limit = period.Amount.Int64
+			limitErr.Used = used.Amount
+			return limitErr
+		}
+	}
+	return nil
+}
+
+// limitPeriods returns the UTC day and month around now with their limits
+func limitPeriods(limits TransferLimit, now time.Time) []limitPeriod {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	return []limitPeriod{
+		{
+			Name:     LimitPeriodDaily,
+			Start:    day,
+			ResetsAt: day.AddDate(0, 0, 1),
+			Count:    limits.DailyCount,
+			Amount:   limits.DailyAmount,
+		},
+		{
+			Name:     LimitPeriodMonthly,
+			Start:    month,
+			ResetsAt: month.AddDate(0, 1, 0),
+			Count:    limits.MonthlyCount,
+			Amount:   limits.MonthlyAmount,
+		},
+	}
+}
+
+// mergeTransferLimits overrides the limits of base that are set in override
+func mergeTransferLimits(base TransferLimit, override TransferLimit) TransferLimit {
+	for _, field := range []struct{ dst, src *sql.NullInt64 }{
+		{&base.DailyCount, &override.DailyCount},
+		{&base.DailyAmount, &override.DailyAmount},
+		{&base.MonthlyCount, &override.MonthlyCount},
+		{&base.MonthlyAmount, &override.MonthlyAmount},
+	} {
+		if field.src.Valid {
+			*field.dst = *field.src
+		}
+	}
+	return base
+}
@@ -0,0 +1,33 @@
+package db
+
+import "time"
+
+// Statuses of a webhook delivery, pending ones are picked by the dispatcher
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryDead is a delivery that ran out of attempts, it waits to be replayed
+	WebhookDeliveryDead = "dead"
+)
+
+const (
+	// MaxWebhookAttempts is how many times a delivery is tried before it is dead-lettered
+	MaxWebhookAttempts = 8
+	// WebhookDeliveryLease is how long a claimed delivery is left to its worker before it is retried
+	WebhookDeliveryLease  = time.Minute
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = 6 * time.Hour
+)
+
+// WebhookRetryBackoff is the delay before retrying a delivery that failed attempt times
+func WebhookRetryBackoff(attempt int32) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := int32(1); i < attempt && delay < webhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > webhookRetryMaxDelay {
+		delay = webhookRetryMaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CSV columns, the header names them in any order and reference is optional.
+// Amounts are in major units of the currency, e.g. 12.50.
+const (
+	csvFromAccountID = "from_account_id"
+	csvToAccountID   = "to_account_id"
+	csvAmount        = "amount"
+	csvCurrency      = "currency"
+	csvReference     = "reference"
+)
+
+var csvRequiredColumns = []string{csvFromAccountID, csvToAccountID, csvAmount, csvCurrency}
+
+func parseCSV(r io.Reader) ([]Line, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("empty csv file")
+		}
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range csvRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv header has no %s column", name)
+		}
+	}
+
+	lines := []Line{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		number, _ := reader.FieldPos(0)
+		lines = append(lines, csvLine(number, record, columns))
+	}
+}
+
+// csvLine reads a record, the first field that can't be read is the error of the line
+func csvLine(number int, record []string, columns map[string]int) Line {
+	line := Line{Number: number}
+
+	if len(record) != len(columns) {
+		line.err = fmt.Errorf("expected %d fields, got %d", len(columns), len(record))
+		return line
+	}
+
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	line.Reference = field(csvReference)
+	line.Currency = strings.ToUpper(field(csvCurrency))
+
+	var err error
+	if line.FromAccountID, err = parseAccountID(field(csvFromAccountID)); err != nil {
+		line.err = err
+		return line
+	}
+
+	if line.ToAccountID, err = parseAccountID(field(csvToAccountID)); err != nil {
+		line.err = err
+		return line
+	}
+
+	line.Amount, line.err = parseAmount(field(csvAmount), line.Currency)
+	return line
+}
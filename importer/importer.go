@@ -0,0 +1,229 @@
+// Package importer reads files of payment instructions into transfers, validates them as a whole
+// and runs them one by one
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"simplebank/currency"
+	db "simplebank/db/sqlc"
+	"strconv"
+	"strings"
+)
+
+// Formats of the files that can be imported
+const (
+	FormatCSV     = "csv"
+	FormatPain001 = "pain001"
+)
+
+// Statuses of a line in the report of an import
+const (
+	// StatusValid is a line that passed validation but wasn't run, in a dry run or because others are invalid
+	StatusValid     = "valid"
+	StatusInvalid   = "invalid"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Line is a payment instruction of a file, amounts are in minor units of the currency
+type Line struct {
+	// Number is the position of the instruction in the file: the line of a CSV record,
+	// the rank of a pain.001 transaction
+	Number        int    `json:"line"`
+	Reference     string `json:"reference,omitempty"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// err is why the instruction couldn't be read
+	err error
+}
+
+// Err returns why the instruction couldn't be read, if it couldn't
+func (line Line) Err() error {
+	return line.err
+}
+
+type LineResult struct {
+	Line
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	TransferID int64  `json:"transfer_id,omitempty"`
+	Fee        int64  `json:"fee,omitempty"`
+}
+
+type RunParams struct {
+	// Owner must own every source account, empty skips the check for operators
+	Owner  string `json:"owner"`
+	DryRun bool   `json:"dry_run"`
+}
+
+type Report struct {
+	DryRun    bool         `json:"dry_run"`
+	Total     int          `json:"total"`
+	Invalid   int          `json:"invalid"`
+	Completed int          `json:"completed"`
+	Failed    int          `json:"failed"`
+	Lines     []LineResult `json:"lines"`
+}
+
+// OK tells if every line is valid and none failed
+func (report Report) OK() bool {
+	return report.Invalid == 0 && report.Failed == 0
+}
+
+// DetectFormat guesses the format of a file from its name
+func DetectFormat(fileName string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return FormatCSV, true
+	case ".xml":
+		return FormatPain001, true
+	}
+	return "", false
+}
+
+// Parse reads the instructions of a file. Instructions that can't be read are returned with their
+// error, an error is only returned when the file as a whole can't be read.
+func Parse(format string, r io.Reader) ([]Line, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatPain001:
+		return parsePain001(r)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// Run validates every line first: the accounts must exist, be in the currency of the line and the
+// source must belong to the owner. Only when they all pass, and it isn't a dry run, the lines are
+// run one transfer each, a line that fails doesn't stop the next ones.
+func Run(ctx context.Context, store db.Store, lines []Line, arg RunParams) (Report, error) {
+	report := Report{
+		DryRun: arg.DryRun,
+		Total:  len(lines),
+		Lines:  make([]LineResult, len(lines)),
+	}
+
+	accounts, err := lineAccounts(ctx, store, lines)
+	if err != nil {
+		return report, err
+	}
+
+	for i, line := range lines {
+		report.Lines[i] = LineResult{Line: line, Status: StatusValid}
+
+		if err := validateLine(line, accounts, arg.Owner); err != nil {
+			report.Lines[i].Status = StatusInvalid
+			report.Lines[i].Error = err.Error()
+			report.Invalid++
+		}
+	}
+
+	if report.Invalid > 0 || arg.DryRun {
+		return report, nil
+	}
+
+	for i := range report.Lines {
+		res := &report.Lines[i]
+
+		transfer, err := store.TransferTx(ctx, db.TransferTxParams{
+			FromAccountID: res.FromAccountID,
+			ToAccountID:   res.ToAccountID,
+			Amount:        res.Amount,
+		})
+		if err != nil {
+			res.Status = StatusFailed
+			res.Error = err.Error()
+			report.Failed++
+			continue
+		}
+
+		res.Status = StatusCompleted
+		res.TransferID = transfer.Transfer.ID
+		res.Fee = transfer.Fee
+		report.Completed++
+	}
+
+	return report, nil
+}
+
+// lineAccounts fetches every account of the lines in one query, keyed by id
+func lineAccounts(ctx context.Context, store db.Store, lines []Line) (map[int64]db.Account, error) {
+	ids := make([]int64, 0, 2*len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.FromAccountID, line.ToAccountID)
+	}
+
+	list, err := store.ListAccountsByID(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[int64]db.Account, len(list))
+	for _, acc := range list {
+		accounts[acc.ID] = acc
+	}
+	return accounts, nil
+}
+
+// validateLine checks a line against the accounts before anything runs
+func validateLine(line Line, accounts map[int64]db.Account, owner string) error {
+	if line.err != nil {
+		return line.err
+	}
+
+	if line.Amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+
+	if line.FromAccountID == line.ToAccountID {
+		return errors.New("from and to accounts are the same")
+	}
+
+	for _, id := range []int64{line.FromAccountID, line.ToAccountID} {
+		acc, ok := accounts[id]
+		if !ok {
+			return fmt.Errorf("account [%d] not found", id)
+		}
+
+		// house accounts only move through the ledger's own postings
+		if acc.Kind != db.AccountKindCustomer {
+			return fmt.Errorf("account [%d] is a house account", id)
+		}
+
+		if acc.Currency != line.Currency {
+			return fmt.Errorf("account [%d] currency mismatch %s vs %s", id, acc.Currency, line.Currency)
+		}
+	}
+
+	if owner != "" && accounts[line.FromAccountID].Owner != owner {
+		return fmt.Errorf("account [%d] doesn't belong to %s", line.FromAccountID, owner)
+	}
+
+	return nil
+}
+
+// parseAccountID reads an account id of a file
+func parseAccountID(s string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid account id %q", s)
+	}
+	return id, nil
+}
+
+// parseAmount reads an amount in major units of code into minor units
+func parseAmount(s string, code string) (int64, error) {
+	cur, ok := currency.Default().Lookup(code)
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency %q", code)
+	}
+	return currency.ParseAmount(strings.TrimSpace(s), cur)
+}
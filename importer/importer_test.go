@@ -0,0 +1,230 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	file := `reference,from_account_id,to_account_id,amount,currency
+inv-1,1,2,12.50,usd
+inv-2,1,3,0.05,USD
+
+inv-3,x,2,1,USD
+inv-4,1,2,1.234,USD
+inv-5,1,2
+`
+	lines, err := Parse(FormatCSV, strings.NewReader(file))
+	require.NoError(t, err)
+	require.Len(t, lines, 5)
+
+	require.Equal(t, Line{Number: 2, Reference: "inv-1", FromAccountID: 1, ToAccountID: 2, Amount: 1250, Currency: "USD"}, lines[0])
+	require.Equal(t, int64(5), lines[1].Amount)
+
+	// blank lines are skipped, numbers are still the lines of the file
+	require.Equal(t, 5, lines[2].Number)
+	require.ErrorContains(t, lines[2].Err(), "invalid account id")
+	require.ErrorContains(t, lines[3].Err(), "decimal places")
+	require.ErrorContains(t, lines[4].Err(), "expected 5 fields")
+
+	_, err = Parse(FormatCSV, strings.NewReader("from_account_id,to_account_id,amount\n1,2,3\n"))
+	require.ErrorContains(t, err, "no currency column")
+
+	_, err = Parse(FormatCSV, strings.NewReader(""))
+	require.Error(t, err)
+}
+
+const testPain001 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>MSG-1</MsgId>
+      <NbOfTxs>3</NbOfTxs>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>PMT-1</PmtInfId>
+      <DbtrAcct><Id><Othr><Id>1</Id></Othr></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-1</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">100.00</InstdAmt></Amt>
+        <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>NOTPROVIDED</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">0.99</InstdAmt></Amt>
+        <CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+    <PmtInf>
+      <PmtInfId>PMT-2</PmtInfId>
+      <DbtrAcct><Id><Othr><Id>3</Id></Othr></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-3</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">5</InstdAmt></Amt>
+        <CdtrAcct><Id><Othr><Id>1</Id></Othr></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+func TestParsePain001(t *testing.T) {
+	lines, err := Parse(FormatPain001, strings.NewReader(testPain001))
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+
+	require.Equal(t, Line{Number: 1, Reference: "E2E-1", FromAccountID: 1, ToAccountID: 2, Amount: 10000, Currency: "EUR"}, lines[0])
+
+	require.Equal(t, 2, lines[1].Number)
+	require.Empty(t, lines[1].Reference)
+	require.ErrorContains(t, lines[1].Err(), "IBAN")
+
+	require.Equal(t, Line{Number: 3, Reference: "E2E-3", FromAccountID: 3, ToAccountID: 1, Amount: 500, Currency: "EUR"}, lines[2])
+
+	truncated := strings.Replace(testPain001, "<NbOfTxs>3</NbOfTxs>", "<NbOfTxs>4</NbOfTxs>", 1)
+	_, err = Parse(FormatPain001, strings.NewReader(truncated))
+	require.ErrorContains(t, err, "announces 4 transactions")
+
+	_, err = Parse(FormatPain001, strings.NewReader("<Document>"))
+	require.Error(t, err)
+
+	_, err = Parse("mt940", strings.NewReader(""))
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestRun(t *testing.T) {
+	accounts := []db.Account{
+		{ID: 1, Owner: "alice", Currency: "USD", Kind: db.AccountKindCustomer},
+		{ID: 2, Owner: "bob", Currency: "USD", Kind: db.AccountKindCustomer},
+		{ID: 3, Owner: "bob", Currency: "EUR", Kind: db.AccountKindCustomer},
+		{ID: 4, Owner: db.HouseOwner, Currency: "USD", Kind: db.AccountKindSuspense},
+	}
+	valid := []Line{
+		{Number: 2, FromAccountID: 1, ToAccountID: 2, Amount: 100, Currency: "USD"},
+		{Number: 3, FromAccountID: 1, ToAccountID: 2, Amount: 200, Currency: "USD"},
+	}
+
+	testCases := []struct {
+		name          string
+		lines         []Line
+		arg           RunParams
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, report Report, err error)
+	}{
+		{
+			name:  "OK",
+			lines: valid,
+			arg:   RunParams{Owner: "alice"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Eq([]int64{1, 2, 1, 2})).Times(1).Return(accounts, nil)
+				gomock.InOrder(
+					store.EXPECT().
+						TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{FromAccountID: 1, ToAccountID: 2, Amount: 100})).
+						Times(1).
+						Return(db.TransferTxResult{Transfer: db.Transfer{ID: 10}, Fee: 1}, nil),
+					store.EXPECT().
+						TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{FromAccountID: 1, ToAccountID: 2, Amount: 200})).
+						Times(1).
+						Return(db.TransferTxResult{}, &db.InsufficientFundsError{AccountID: 1}),
+				)
+			},
+			checkResponse: func(t *testing.T, report Report, err error) {
+				require.NoError(t, err)
+				require.False(t, report.OK())
+				require.Equal(t, 2, report.Total)
+				require.Equal(t, 1, report.Completed)
+				require.Equal(t, 1, report.Failed)
+
+				require.Equal(t, StatusCompleted, report.Lines[0].Status)
+				require.Equal(t, int64(10), report.Lines[0].TransferID)
+				require.Equal(t, int64(1), report.Lines[0].Fee)
+
+				// a failed line doesn't undo the previous ones
+				require.Equal(t, StatusFailed, report.Lines[1].Status)
+				require.NotEmpty(t, report.Lines[1].Error)
+			},
+		},
+		{
+			name:  "DryRun",
+			lines: valid,
+			arg:   RunParams{DryRun: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Any()).Times(1).Return(accounts, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, report Report, err error) {
+				require.NoError(t, err)
+				require.True(t, report.OK())
+				require.True(t, report.DryRun)
+				require.Zero(t, report.Completed)
+				for _, line := range report.Lines {
+					require.Equal(t, StatusValid, line.Status)
+				}
+			},
+		},
+		{
+			name: "Invalid",
+			lines: append([]Line{
+				{Number: 4, FromAccountID: 1, ToAccountID: 3, Amount: 100, Currency: "USD"},
+				{Number: 5, FromAccountID: 2, ToAccountID: 1, Amount: 100, Currency: "USD"},
+				{Number: 6, FromAccountID: 1, ToAccountID: 9, Amount: 100, Currency: "USD"},
+				{Number: 7, FromAccountID: 1, ToAccountID: 2, Amount: 0, Currency: "USD"},
+				{Number: 8, FromAccountID: 1, ToAccountID: 1, Amount: 100, Currency: "USD"},
+				{Number: 9, err: sql.ErrNoRows},
+				{Number: 10, FromAccountID: 1, ToAccountID: 4, Amount: 100, Currency: "USD"},
+			}, valid...),
+			arg: RunParams{Owner: "alice"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Any()).Times(1).Return(accounts, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, report Report, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 7, report.Invalid)
+				require.Zero(t, report.Completed)
+
+				errs := []string{"currency mismatch", "doesn't belong to alice", "[9] not found", "positive", "same", sql.ErrNoRows.Error(), "[4] is a house account"}
+				for i, msg := range errs {
+					require.Equal(t, StatusInvalid, report.Lines[i].Status)
+					require.Contains(t, report.Lines[i].Error, msg)
+				}
+
+				// valid lines aren't run while others are invalid
+				require.Equal(t, StatusValid, report.Lines[7].Status)
+				require.Equal(t, StatusValid, report.Lines[8].Status)
+			},
+		},
+		{
+			name:  "InternalError",
+			lines: valid,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByID(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, report Report, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			report, err := Run(context.Background(), store, tc.lines, tc.arg)
+			tc.checkResponse(t, report, err)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// painNotProvided is the end to end id of transactions the initiating party gave no reference
+const painNotProvided = "NOTPROVIDED"
+
+// painDocument is the part of an ISO 20022 pain.001 customer credit transfer initiation the importer
+// reads. Elements are matched by local name so any version of the message is accepted.
+type painDocument struct {
+	XMLName      xml.Name          `xml:"Document"`
+	NbOfTxs      string            `xml:"CstmrCdtTrfInitn>GrpHdr>NbOfTxs"`
+	PaymentInfos []painPaymentInfo `xml:"CstmrCdtTrfInitn>PmtInf"`
+}
+
+type painPaymentInfo struct {
+	DebtorAccount painAccount       `xml:"DbtrAcct"`
+	Transactions  []painTransaction `xml:"CdtTrfTxInf"`
+}
+
+// painAccount is identified by the account id as its other identification, IBANs aren't ours
+type painAccount struct {
+	Other string `xml:"Id>Othr>Id"`
+	IBAN  string `xml:"Id>IBAN"`
+}
+
+type painTransaction struct {
+	EndToEndID string `xml:"PmtId>EndToEndId"`
+	Amount     struct {
+		Currency string `xml:"Ccy,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"Amt>InstdAmt"`
+	CreditorAccount painAccount `xml:"CdtrAcct"`
+}
+
+func parsePain001(r io.Reader) ([]Line, error) {
+	var doc painDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid pain.001 file: %w", err)
+	}
+
+	if len(doc.PaymentInfos) == 0 {
+		return nil, errors.New("pain.001 file has no payment information")
+	}
+
+	lines := []Line{}
+	for _, info := range doc.PaymentInfos {
+		for _, tx := range info.Transactions {
+			lines = append(lines, painLine(len(lines)+1, info.DebtorAccount, tx))
+		}
+	}
+
+	// the control count guards against truncated files
+	if count := strings.TrimSpace(doc.NbOfTxs); count != "" && count != strconv.Itoa(len(lines)) {
+		return nil, fmt.Errorf("pain.001 file announces %s transactions but holds %d", count, len(lines))
+	}
+
+	return lines, nil
+}
+
+// painLine reads a transaction, paid from the debtor account of its payment information
+func painLine(number int, debtor painAccount, tx painTransaction) Line {
+	line := Line{
+		Number:   number,
+		Currency: strings.ToUpper(strings.TrimSpace(tx.Amount.Currency)),
+	}
+
+	if reference := strings.TrimSpace(tx.EndToEndID); reference != painNotProvided {
+		line.Reference = reference
+	}
+
+	var err error
+	if line.FromAccountID, err = painAccountID(debtor); err != nil {
+		line.err = fmt.Errorf("debtor: %w", err)
+		return line
+	}
+
+	if line.ToAccountID, err = painAccountID(tx.CreditorAccount); err != nil {
+		line.err = fmt.Errorf("creditor: %w", err)
+		return line
+	}
+
+	line.Amount, line.err = parseAmount(tx.Amount.Value, line.Currency)
+	return line
+}
+
+func painAccountID(acc painAccount) (int64, error) {
+	if acc.Other == "" && acc.IBAN != "" {
+		return 0, fmt.Errorf("IBAN %s is not an account of this bank", acc.IBAN)
+	}
+	return parseAccountID(acc.Other)
+}
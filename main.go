@@ -1,11 +1,20 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"flag"
 	"log"
+	"os"
 	"simplebank/api"
+	"simplebank/currency"
 	db "simplebank/db/sqlc"
+	"simplebank/importer"
+	"simplebank/token"
 	"simplebank/util"
+	"simplebank/worker"
+	"time"
 
 	_ "github.com/lib/pq"
 )
@@ -23,8 +32,82 @@ func main() {
 		log.Fatal("Cannot connect to Postgres: ", err)
 	}
 
+	if config.CurrenciesFile != "" {
+		registry, err := currency.LoadRegistry(config.CurrenciesFile)
+		if err != nil {
+			log.Fatal("cannot load currencies: ", err)
+		}
+		currency.SetDefault(registry)
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		log.Fatal("cannot create token maker: ", err)
+	}
+
+	fxRates := util.NewStaticFXRateProvider(nil)
+	if config.FXRatesFile != "" {
+		fxRates, err = util.LoadFXRates(config.FXRatesFile)
+		if err != nil {
+			log.Fatal("cannot load fx rates: ", err)
+		}
+	}
+
 	store := db.NewStore(conn)
-	server := api.NewServer(store)
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reconcile":
+			os.Exit(runReconcile(context.Background(), store, config, os.Args[2:]))
+		case "import":
+			os.Exit(runImport(context.Background(), store, os.Args[2:]))
+		case "verify-audit":
+			os.Exit(runVerifyAudit(context.Background(), store, os.Args[2:]))
+		case "accrue-interest":
+			os.Exit(runAccrueInterest(context.Background(), store, os.Args[2:]))
+		case "rebuild-snapshots":
+			os.Exit(runRebuildSnapshots(context.Background(), store, os.Args[2:]))
+		}
+	}
+
+	// every configured currency gets its house accounts, on the first start after it is added
+	if _, err := store.SeedHouseAccountsTx(context.Background(), currency.Default().Codes()); err != nil {
+		log.Fatal("cannot seed house accounts: ", err)
+	}
+
+	if config.FeeSchedulesFile != "" {
+		if err := loadFeeSchedules(context.Background(), store, config.FeeSchedulesFile); err != nil {
+			log.Fatal("cannot load fee schedules: ", err)
+		}
+	}
+
+	if config.SuspenseAccountsFile != "" {
+		if err := loadSuspenseAccounts(context.Background(), store, config.SuspenseAccountsFile); err != nil {
+			log.Fatal("cannot load suspense accounts: ", err)
+		}
+	}
+
+	if config.AccountProductsFile != "" {
+		if err := loadAccountProducts(context.Background(), store, config.AccountProductsFile); err != nil {
+			log.Fatal("cannot load account products: ", err)
+		}
+	}
+
+	if err := loadTransferLimitDefaults(context.Background(), store, config); err != nil {
+		log.Fatal("cannot load transfer limits: ", err)
+	}
+
+	go worker.NewScheduledTransferWorker(store, pollInterval(config.ScheduledTransferInterval)).Start(context.Background())
+	go worker.NewHoldExpiryWorker(store, pollInterval(config.HoldExpiryInterval)).Start(context.Background())
+	go worker.NewWebhookWorker(store, pollInterval(config.WebhookInterval)).Start(context.Background())
+	go worker.NewInterestWorker(store, pollInterval(config.InterestInterval), db.DefaultInterestChunkSize).Start(context.Background())
+	go worker.NewBalanceSnapshotWorker(store, pollInterval(config.BalanceSnapshotInterval), db.DefaultSnapshotChunkSize).Start(context.Background())
+
+	if config.ReconcileInterval > 0 {
+		go worker.NewReconcileWorker(store, config.ReconcileInterval, config.ReconcileChunkSize).Start(context.Background())
+	}
+
+	server := api.NewServer(config, store, tokenMaker, fxRates)
 
 	err = server.Start(config.ServerAdress)
 
@@ -32,3 +115,299 @@ func main() {
 		log.Fatal("Cannot start server")
 	}
 }
+
+// pollInterval defaults the interval of a background worker to a minute
+func pollInterval(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return time.Minute
+	}
+	return interval
+}
+
+// loadFeeSchedules writes the fee rules of the config file to the fee_schedules table,
+// rules already in the table for the same currency and tier are replaced
+func loadFeeSchedules(ctx context.Context, store db.Store, path string) error {
+	schedules, err := util.LoadFeeSchedules(path)
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		_, err := store.UpsertFeeSchedule(ctx, db.UpsertFeeScheduleParams{
+			Currency:      schedule.Currency,
+			Tier:          schedule.Tier,
+			FlatFee:       schedule.FlatFee,
+			PercentageBps: schedule.PercentageBps,
+			MinFee:        schedule.MinFee,
+			MaxFee:        schedule.MaxFee,
+			FeeAccountID:  schedule.FeeAccountID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadSuspenseAccounts writes the suspense accounts of the config file to the suspense_accounts table,
+// the account already set for a currency is replaced
+func loadSuspenseAccounts(ctx context.Context, store db.Store, path string) error {
+	accounts, err := util.LoadSuspenseAccounts(path)
+	if err != nil {
+		return err
+	}
+
+	for _, acc := range accounts {
+		_, err := store.UpsertSuspenseAccount(ctx, db.UpsertSuspenseAccountParams{
+			Currency:  acc.Currency,
+			AccountID: acc.AccountID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadAccountProducts writes the savings products of the config file to the account_products table,
+// a product already in the table with the same name is replaced
+func loadAccountProducts(ctx context.Context, store db.Store, path string) error {
+	products, err := util.LoadAccountProducts(path)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		_, err := store.UpsertAccountProduct(ctx, db.UpsertAccountProductParams{
+			Name:        product.Name,
+			Currency:    product.Currency,
+			RateBps:     product.RateBps,
+			DayCount:    product.DayCount,
+			Compounding: product.Compounding,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTransferLimitDefaults writes the global transfer limits of the config to the transfer_limits table.
+// The defaults in the table are left alone when the config sets none.
+func loadTransferLimitDefaults(ctx context.Context, store db.Store, config util.Config) error {
+	arg := db.UpsertTransferLimitParams{
+		DailyCount:    configLimit(config.TransferDailyCountLimit),
+		DailyAmount:   configLimit(config.TransferDailyAmountLimit),
+		MonthlyCount:  configLimit(config.TransferMonthlyCountLimit),
+		MonthlyAmount: configLimit(config.TransferMonthlyAmountLimit),
+	}
+
+	if !arg.DailyCount.Valid && !arg.DailyAmount.Valid && !arg.MonthlyCount.Valid && !arg.MonthlyAmount.Valid {
+		return nil
+	}
+
+	_, err := store.UpsertTransferLimit(ctx, arg)
+	return err
+}
+
+// configLimit turns a limit of the config into a limit of the table, zero leaves it unset
+func configLimit(limit int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: limit, Valid: limit > 0}
+}
+
+// runReconcile implements the reconcile subcommand: it checks the ledger once and writes the
+// report as JSON to stdout. The exit status is 1 when discrepancies were found.
+func runReconcile(ctx context.Context, store db.Store, config util.Config, args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	chunkSize := flags.Int("chunk-size", int(config.ReconcileChunkSize), "accounts scanned per query")
+	flags.Parse(args)
+
+	res, err := store.ReconcileLedger(ctx, db.ReconcileLedgerParams{
+		ChunkSize: int32(*chunkSize),
+	})
+	if err != nil {
+		log.Println("cannot reconcile ledger: ", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(res); err != nil {
+		log.Println("cannot write report: ", err)
+		return 2
+	}
+
+	if len(res.Discrepancies) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runImport implements the import subcommand: it runs the payment instructions of a CSV or pain.001
+// file and writes the report as JSON to stdout. The exit status is 1 when a line is invalid or failed.
+func runImport(ctx context.Context, store db.Store, args []string) int {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "csv or pain001, guessed from the file name when empty")
+	dryRun := flags.Bool("dry-run", false, "validate the file without moving any money")
+	owner := flags.String("owner", "", "user that must own every source account")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		log.Println("usage: import [-format csv|pain001] [-dry-run] [-owner username] <file>")
+		return 2
+	}
+	path := flags.Arg(0)
+
+	if *format == "" {
+		detected, ok := importer.DetectFormat(path)
+		if !ok {
+			log.Printf("cannot tell the format of %s, set -format", path)
+			return 2
+		}
+		*format = detected
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Println("cannot open file: ", err)
+		return 2
+	}
+	defer file.Close()
+
+	lines, err := importer.Parse(*format, file)
+	if err != nil {
+		log.Println("cannot read file: ", err)
+		return 2
+	}
+
+	report, err := importer.Run(ctx, store, lines, importer.RunParams{
+		Owner:  *owner,
+		DryRun: *dryRun,
+	})
+	if err != nil {
+		log.Println("cannot import transfers: ", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Println("cannot write report: ", err)
+		return 2
+	}
+
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
+
+// runVerifyAudit implements the verify-audit subcommand: it walks the hash chain of the audit log and
+// writes the report as JSON to stdout. The exit status is 1 when the chain is broken.
+func runVerifyAudit(ctx context.Context, store db.Store, args []string) int {
+	flags := flag.NewFlagSet("verify-audit", flag.ExitOnError)
+	chunkSize := flags.Int("chunk-size", db.DefaultAuditChunkSize, "rows read per query")
+	flags.Parse(args)
+
+	res, err := store.VerifyAuditLog(ctx, db.VerifyAuditLogParams{
+		ChunkSize: int32(*chunkSize),
+	})
+	if err != nil {
+		log.Println("cannot verify audit log: ", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(res); err != nil {
+		log.Println("cannot write report: ", err)
+		return 2
+	}
+
+	if len(res.Breaks) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runAccrueInterest implements the accrue-interest subcommand: it accrues the interest of every day through
+// -through, backfilling missed days, capitalizes the compounding periods those days complete, and writes
+// both reports as JSON to stdout
+func runAccrueInterest(ctx context.Context, store db.Store, args []string) int {
+	flags := flag.NewFlagSet("accrue-interest", flag.ExitOnError)
+	through := flags.String("through", time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly), "last day accrued, YYYY-MM-DD")
+	chunkSize := flags.Int("chunk-size", db.DefaultInterestChunkSize, "savings accounts read per query")
+	flags.Parse(args)
+
+	day, err := time.Parse(time.DateOnly, *through)
+	if err != nil {
+		log.Println("invalid -through: ", err)
+		return 2
+	}
+
+	accrued, err := store.AccrueInterest(ctx, db.AccrueInterestParams{
+		Through:   day,
+		ChunkSize: int32(*chunkSize),
+	})
+	if err != nil {
+		log.Println("cannot accrue interest: ", err)
+		return 2
+	}
+
+	capitalized, err := store.CapitalizeInterest(ctx, db.CapitalizeInterestParams{
+		Through:   day,
+		ChunkSize: int32(*chunkSize),
+	})
+	if err != nil {
+		log.Println("cannot capitalize interest: ", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(struct {
+		Accrued     db.AccrueInterestResult     `json:"accrued"`
+		Capitalized db.CapitalizeInterestResult `json:"capitalized"`
+	}{accrued, capitalized})
+	if err != nil {
+		log.Println("cannot write report: ", err)
+		return 2
+	}
+	return 0
+}
+
+// runRebuildSnapshots implements the rebuild-snapshots subcommand: it takes again the balance snapshots
+// taken from -from on, all of them by default, and writes the report as JSON to stdout
+func runRebuildSnapshots(ctx context.Context, store db.Store, args []string) int {
+	flags := flag.NewFlagSet("rebuild-snapshots", flag.ExitOnError)
+	from := flags.String("from", "", "day of the first snapshot rebuilt, YYYY-MM-DD")
+	chunkSize := flags.Int("chunk-size", db.DefaultSnapshotChunkSize, "accounts snapshotted per query")
+	flags.Parse(args)
+
+	var fromTime time.Time
+	if *from != "" {
+		var err error
+		fromTime, err = time.Parse(time.DateOnly, *from)
+		if err != nil {
+			log.Println("invalid -from: ", err)
+			return 2
+		}
+	}
+
+	res, err := store.RebuildBalanceSnapshots(ctx, db.RebuildBalanceSnapshotsParams{
+		From:      fromTime,
+		ChunkSize: int32(*chunkSize),
+	})
+	if err != nil {
+		log.Println("cannot rebuild balance snapshots: ", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(res); err != nil {
+		log.Println("cannot write report: ", err)
+		return 2
+	}
+	return 0
+}
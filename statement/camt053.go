@@ -0,0 +1,183 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"simplebank/currency"
+	db "simplebank/db/sqlc"
+	"strconv"
+	"time"
+)
+
+// camt053Namespace is the version of the ISO 20022 bank to customer statement written
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+// Credit and debit indicators of camt amounts, which are never negative
+const (
+	camtCredit = "CRDT"
+	camtDebit  = "DBIT"
+)
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtDateTime struct {
+	DateTime string `xml:"DtTm"`
+}
+
+type camtGroupHeader struct {
+	XMLName   xml.Name `xml:"GrpHdr"`
+	MessageID string   `xml:"MsgId"`
+	CreatedAt string   `xml:"CreDtTm"`
+}
+
+type camtPeriod struct {
+	XMLName xml.Name `xml:"FrToDt"`
+	From    string   `xml:"FrDtTm"`
+	To      string   `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	XMLName  xml.Name `xml:"Acct"`
+	ID       string   `xml:"Id>Othr>Id"`
+	Currency string   `xml:"Ccy"`
+	Owner    string   `xml:"Ownr>Nm"`
+	Servicer string   `xml:"Svcr>FinInstnId>Nm"`
+}
+
+type camtBalance struct {
+	XMLName   xml.Name     `xml:"Bal"`
+	Type      string       `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount   `xml:"Amt"`
+	Indicator string       `xml:"CdtDbtInd"`
+	Date      camtDateTime `xml:"Dt"`
+}
+
+type camtEntry struct {
+	XMLName      xml.Name          `xml:"Ntry"`
+	Reference    string            `xml:"NtryRef"`
+	Amount       camtAmount        `xml:"Amt"`
+	Indicator    string            `xml:"CdtDbtInd"`
+	Status       string            `xml:"Sts"`
+	BookingDate  camtDateTime      `xml:"BookgDt"`
+	ValueDate    camtDateTime      `xml:"ValDt"`
+	ServicerRef  string            `xml:"AcctSvcrRef,omitempty"`
+	Code         string            `xml:"BkTxCd>Prtry>Cd"`
+	CodeIssuer   string            `xml:"BkTxCd>Prtry>Issr"`
+	Transactions *camtTransactions `xml:"NtryDtls>TxDtls,omitempty"`
+}
+
+type camtTransactions struct {
+	ServicerRef string `xml:"Refs>AcctSvcrRef,omitempty"`
+	// the counterparty is the creditor of debits and the debtor of credits
+	CreditorAccount *camtAccountID `xml:"RltdPties>CdtrAcct,omitempty"`
+	DebtorAccount   *camtAccountID `xml:"RltdPties>DbtrAcct,omitempty"`
+}
+
+type camtAccountID struct {
+	ID string `xml:"Id>Othr>Id"`
+}
+
+// camt053Writer writes an ISO 20022 camt.053 statement with the opening and closing booked balances
+// and a booked Ntry per entry
+type camt053Writer struct {
+	xml       *xmlStream
+	acc       db.Account
+	cur       currency.Currency
+	createdAt time.Time
+}
+
+func newCamt053Writer(out io.Writer, acc db.Account, cur currency.Currency, createdAt time.Time) *camt053Writer {
+	return &camt053Writer{
+		xml:       newXMLStream(out),
+		acc:       acc,
+		cur:       cur,
+		createdAt: createdAt,
+	}
+}
+
+func (w *camt053Writer) WriteHeader(statement db.AccountStatement) error {
+	id := fmt.Sprintf("STMT-%d-%s", w.acc.ID, w.createdAt.UTC().Format("20060102150405"))
+
+	w.xml.header("xml", `version="1.0" encoding="UTF-8"`)
+	w.xml.start("Document", xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace})
+	w.xml.start("BkToCstmrStmt")
+	w.xml.element(camtGroupHeader{MessageID: id, CreatedAt: camtTime(w.createdAt)}, "")
+
+	w.xml.start("Stmt")
+	w.xml.text("Id", id)
+	w.xml.text("CreDtTm", camtTime(w.createdAt))
+	w.xml.element(camtPeriod{From: camtTime(statement.From), To: camtTime(statement.To)}, "")
+	w.xml.element(camtAccount{
+		ID:       strconv.FormatInt(w.acc.ID, 10),
+		Currency: w.cur.Code,
+		Owner:    w.acc.Owner,
+		Servicer: ServicerName,
+	}, "")
+	w.xml.element(w.balance("OPBD", statement.OpeningBalance, statement.From), "")
+	w.xml.element(w.balance("CLBD", statement.ClosingBalance, statement.To), "")
+	return w.xml.flush()
+}
+
+func (w *camt053Writer) WriteEntry(entry db.ListStatementEntriesRow) error {
+	ntry := camtEntry{
+		Reference:   strconv.FormatInt(entry.ID, 10),
+		Amount:      w.amount(entry.Amount),
+		Indicator:   camtIndicator(entry.Amount),
+		Status:      "BOOK",
+		BookingDate: camtDateTime{camtTime(entry.CreatedAt)},
+		ValueDate:   camtDateTime{camtTime(entry.CreatedAt)},
+		Code:        "ADJUSTMENT",
+		CodeIssuer:  ServicerName,
+	}
+
+	if entry.TransferID.Valid {
+		ntry.ServicerRef = strconv.FormatInt(entry.TransferID.Int64, 10)
+		ntry.Code = "TRANSFER"
+		ntry.Transactions = &camtTransactions{ServicerRef: ntry.ServicerRef}
+
+		if entry.CounterpartyAccountID.Valid {
+			counterparty := &camtAccountID{ID: strconv.FormatInt(entry.CounterpartyAccountID.Int64, 10)}
+			if entry.Amount < 0 {
+				ntry.Transactions.CreditorAccount = counterparty
+			} else {
+				ntry.Transactions.DebtorAccount = counterparty
+			}
+		}
+	}
+
+	w.xml.element(ntry, "")
+	return w.xml.err
+}
+
+func (w *camt053Writer) Close() error {
+	return w.xml.close()
+}
+
+func (w *camt053Writer) balance(code string, amount int64, at time.Time) camtBalance {
+	return camtBalance{
+		Type:      code,
+		Amount:    w.amount(amount),
+		Indicator: camtIndicator(amount),
+		Date:      camtDateTime{camtTime(at)},
+	}
+}
+
+func (w *camt053Writer) amount(amount int64) camtAmount {
+	return camtAmount{Currency: w.cur.Code, Value: magnitude(amount, w.cur)}
+}
+
+func camtIndicator(amount int64) string {
+	if amount < 0 {
+		return camtDebit
+	}
+	return camtCredit
+}
+
+// camtTime formats a time as an ISO 8601 date time in UTC
+func camtTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
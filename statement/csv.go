@@ -0,0 +1,60 @@
+package statement
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"io"
+	"simplebank/currency"
+	db "simplebank/db/sqlc"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{
+	"entry_id",
+	"booked_at",
+	"amount",
+	"currency",
+	"balance",
+	"transfer_id",
+	"counterparty_account_id",
+}
+
+// csvWriter writes a row per entry with amounts in major units, the balance is the one after the entry
+type csvWriter struct {
+	w   *csv.Writer
+	cur currency.Currency
+}
+
+func newCSVWriter(out io.Writer, cur currency.Currency) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(out), cur: cur}
+}
+
+func (w *csvWriter) WriteHeader(statement db.AccountStatement) error {
+	return w.w.Write(csvHeader)
+}
+
+func (w *csvWriter) WriteEntry(entry db.ListStatementEntriesRow) error {
+	return w.w.Write([]string{
+		strconv.FormatInt(entry.ID, 10),
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		currency.New(entry.Amount, w.cur).Format(),
+		w.cur.Code,
+		currency.New(entry.RunningBalance, w.cur).Format(),
+		nullID(entry.TransferID),
+		nullID(entry.CounterpartyAccountID),
+	})
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// nullID formats an optional id, an unset one is left empty
+func nullID(id sql.NullInt64) string {
+	if !id.Valid {
+		return ""
+	}
+	return strconv.FormatInt(id.Int64, 10)
+}
@@ -0,0 +1,137 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"simplebank/currency"
+	db "simplebank/db/sqlc"
+	"strconv"
+	"time"
+)
+
+// ofxHeader is the processing instruction of OFX 2.2 files
+const ofxHeader = `OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"`
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+var ofxOK = ofxStatus{Code: 0, Severity: "INFO"}
+
+type ofxSignOn struct {
+	XMLName  xml.Name  `xml:"SONRS"`
+	Status   ofxStatus `xml:"STATUS"`
+	Server   string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+type ofxBankAccount struct {
+	XMLName xml.Name `xml:"BANKACCTFROM"`
+	BankID  string   `xml:"BANKID"`
+	AcctID  string   `xml:"ACCTID"`
+	Type    string   `xml:"ACCTTYPE"`
+}
+
+type ofxTransaction struct {
+	XMLName xml.Name `xml:"STMTTRN"`
+	Type    string   `xml:"TRNTYPE"`
+	Posted  string   `xml:"DTPOSTED"`
+	Amount  string   `xml:"TRNAMT"`
+	FITID   string   `xml:"FITID"`
+	Name    string   `xml:"NAME,omitempty"`
+	Memo    string   `xml:"MEMO,omitempty"`
+}
+
+type ofxBalance struct {
+	Amount string `xml:"BALAMT"`
+	AsOf   string `xml:"DTASOF"`
+}
+
+// ofxWriter writes an OFX 2.2 bank statement response, entries are its STMTTRN transactions
+type ofxWriter struct {
+	xml       *xmlStream
+	acc       db.Account
+	cur       currency.Currency
+	createdAt time.Time
+	statement db.AccountStatement
+}
+
+func newOFXWriter(out io.Writer, acc db.Account, cur currency.Currency, createdAt time.Time) *ofxWriter {
+	return &ofxWriter{
+		xml:       newXMLStream(out),
+		acc:       acc,
+		cur:       cur,
+		createdAt: createdAt,
+	}
+}
+
+func (w *ofxWriter) WriteHeader(statement db.AccountStatement) error {
+	w.statement = statement
+
+	w.xml.header("xml", `version="1.0" encoding="UTF-8" standalone="no"`)
+	w.xml.header("OFX", ofxHeader)
+
+	w.xml.start("OFX")
+
+	w.xml.start("SIGNONMSGSRSV1")
+	w.xml.element(ofxSignOn{Status: ofxOK, Server: ofxTime(w.createdAt), Language: "ENG"}, "")
+	w.xml.end()
+
+	w.xml.start("BANKMSGSRSV1")
+	w.xml.start("STMTTRNRS")
+	w.xml.text("TRNUID", "0")
+	w.xml.element(ofxOK, "STATUS")
+
+	w.xml.start("STMTRS")
+	w.xml.text("CURDEF", w.cur.Code)
+	w.xml.element(ofxBankAccount{
+		BankID: ServicerName,
+		AcctID: strconv.FormatInt(w.acc.ID, 10),
+		Type:   "CHECKING",
+	}, "")
+
+	w.xml.start("BANKTRANLIST")
+	w.xml.text("DTSTART", ofxTime(statement.From))
+	w.xml.text("DTEND", ofxTime(statement.To))
+	return w.xml.flush()
+}
+
+func (w *ofxWriter) WriteEntry(entry db.ListStatementEntriesRow) error {
+	transaction := ofxTransaction{
+		Type:   "CREDIT",
+		Posted: ofxTime(entry.CreatedAt),
+		Amount: currency.New(entry.Amount, w.cur).Format(),
+		FITID:  strconv.FormatInt(entry.ID, 10),
+	}
+	if entry.Amount < 0 {
+		transaction.Type = "DEBIT"
+	}
+	if entry.CounterpartyAccountID.Valid {
+		transaction.Name = fmt.Sprintf("Account %d", entry.CounterpartyAccountID.Int64)
+	}
+	if entry.TransferID.Valid {
+		transaction.Memo = fmt.Sprintf("Transfer %d", entry.TransferID.Int64)
+	}
+
+	w.xml.element(transaction, "")
+	return w.xml.err
+}
+
+func (w *ofxWriter) Close() error {
+	// BANKTRANLIST
+	w.xml.end()
+
+	w.xml.element(ofxBalance{
+		Amount: currency.New(w.statement.ClosingBalance, w.cur).Format(),
+		AsOf:   ofxTime(w.statement.To),
+	}, "LEDGERBAL")
+
+	return w.xml.close()
+}
+
+// ofxTime formats a time the way OFX dates are, in UTC with its offset
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
@@ -0,0 +1,111 @@
+// Package statement renders account statements in the formats accounting tools import
+package statement
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"simplebank/currency"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+// Formats a statement can be exported in
+const (
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+	FormatOFX     = "ofx"
+	FormatCamt053 = "camt053"
+)
+
+// ServicerName identifies the bank in the exports, e.g. as the OFX bank id
+const ServicerName = "SIMPLEBANK"
+
+var ErrUnknownFormat = errors.New("unknown statement format")
+
+// formats lists the media type and file extension of every format, JSON first as the default
+var formats = []struct {
+	name      string
+	mediaType string
+	extension string
+}{
+	{FormatJSON, "application/json", "json"},
+	{FormatCSV, "text/csv", "csv"},
+	{FormatOFX, "application/x-ofx", "ofx"},
+	{FormatCamt053, "application/vnd.iso20022.camt.053+xml", "xml"},
+}
+
+// Writer renders a statement as it is streamed, Close writes what follows the last entry
+type Writer interface {
+	db.StatementWriter
+	Close() error
+}
+
+// NewWriter creates the writer of a format for a statement of acc, created at createdAt
+func NewWriter(format string, out io.Writer, acc db.Account, createdAt time.Time) (Writer, error) {
+	cur, ok := currency.Default().Lookup(acc.Currency)
+	if !ok {
+		return nil, fmt.Errorf("unsupported currency %q", acc.Currency)
+	}
+
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(out, cur), nil
+	case FormatOFX:
+		return newOFXWriter(out, acc, cur, createdAt), nil
+	case FormatCamt053:
+		return newCamt053Writer(out, acc, cur, createdAt), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// MediaTypes returns the media types of the formats, the default one first
+func MediaTypes() []string {
+	mediaTypes := make([]string, len(formats))
+	for i, format := range formats {
+		mediaTypes[i] = format.mediaType
+	}
+	return mediaTypes
+}
+
+// FormatOf returns the format served as mediaType
+func FormatOf(mediaType string) (string, bool) {
+	for _, format := range formats {
+		if format.mediaType == mediaType {
+			return format.name, true
+		}
+	}
+	return "", false
+}
+
+// MediaType returns the media type of a format
+func MediaType(format string) string {
+	for _, f := range formats {
+		if f.name == format {
+			return f.mediaType
+		}
+	}
+	return "application/octet-stream"
+}
+
+// FileName names the export of a statement, e.g. statement-12-20300101-20300201.csv
+func FileName(format string, statement db.AccountStatementTxParams) string {
+	extension := format
+	for _, f := range formats {
+		if f.name == format {
+			extension = f.extension
+		}
+	}
+
+	return fmt.Sprintf("statement-%d-%s-%s.%s", statement.AccountID,
+		statement.From.UTC().Format("20060102"), statement.To.UTC().Format("20060102"), extension)
+}
+
+// magnitude formats the absolute value of an amount in major units, for formats that carry the sign apart
+func magnitude(amount int64, cur currency.Currency) string {
+	formatted := currency.New(amount, cur).Format()
+	if amount < 0 {
+		return formatted[1:]
+	}
+	return formatted
+}
@@ -0,0 +1,182 @@
+package statement
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/xml"
+	"simplebank/currency"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testFrom = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	testTo   = time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func testAccount() db.Account {
+	return db.Account{ID: 7, Owner: "alice", Currency: currency.EUR}
+}
+
+func testStatement() db.AccountStatement {
+	return db.AccountStatement{
+		AccountID:      7,
+		From:           testFrom,
+		To:             testTo,
+		OpeningBalance: 1000,
+		ClosingBalance: -250,
+		Entries: []db.ListStatementEntriesRow{
+			{
+				ID:                    1,
+				Amount:                -1500,
+				TransferID:            sql.NullInt64{Int64: 40, Valid: true},
+				CreatedAt:             testFrom.Add(time.Hour),
+				CounterpartyAccountID: sql.NullInt64{Int64: 8, Valid: true},
+				RunningBalance:        -500,
+			},
+			{
+				ID:             2,
+				Amount:         250,
+				CreatedAt:      testFrom.Add(2 * time.Hour),
+				RunningBalance: -250,
+			},
+		},
+	}
+}
+
+// writeStatement streams a statement through the writer of format
+func writeStatement(t *testing.T, format string, statement db.AccountStatement) []byte {
+	var out bytes.Buffer
+	w, err := NewWriter(format, &out, testAccount(), testTo)
+	require.NoError(t, err)
+
+	entries := statement.Entries
+	statement.Entries = nil
+
+	require.NoError(t, w.WriteHeader(statement))
+	for _, entry := range entries {
+		require.NoError(t, w.WriteEntry(entry))
+	}
+	require.NoError(t, w.Close())
+
+	return out.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(writeStatement(t, FormatCSV, testStatement()))).ReadAll()
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{
+		csvHeader,
+		{"1", "2030-01-01T01:00:00Z", "-15.00", "EUR", "-5.00", "40", "8"},
+		{"2", "2030-01-01T02:00:00Z", "2.50", "EUR", "-2.50", "", ""},
+	}, records)
+}
+
+func TestOFX(t *testing.T) {
+	out := writeStatement(t, FormatOFX, testStatement())
+	require.Contains(t, string(out), `<?OFX `+ofxHeader+`?>`)
+
+	var doc struct {
+		XMLName xml.Name `xml:"OFX"`
+		Status  int      `xml:"SIGNONMSGSRSV1>SONRS>STATUS>CODE"`
+		Rs      struct {
+			Currency string         `xml:"CURDEF"`
+			Account  ofxBankAccount `xml:"BANKACCTFROM"`
+			Start    string         `xml:"BANKTRANLIST>DTSTART"`
+			End      string         `xml:"BANKTRANLIST>DTEND"`
+			// STMTTRN elements keep their XMLName when decoded
+			Transactions []ofxTransaction `xml:"BANKTRANLIST>STMTTRN"`
+			Balance      ofxBalance       `xml:"LEDGERBAL"`
+		} `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS"`
+	}
+	require.NoError(t, xml.Unmarshal(out, &doc))
+
+	require.Equal(t, "EUR", doc.Rs.Currency)
+	require.Equal(t, "7", doc.Rs.Account.AcctID)
+	require.Equal(t, "20300101000000.000[0:GMT]", doc.Rs.Start)
+	require.Equal(t, "20300201000000.000[0:GMT]", doc.Rs.End)
+	require.Equal(t, "-2.50", doc.Rs.Balance.Amount)
+
+	require.Len(t, doc.Rs.Transactions, 2)
+	require.Equal(t, "DEBIT", doc.Rs.Transactions[0].Type)
+	require.Equal(t, "-15.00", doc.Rs.Transactions[0].Amount)
+	require.Equal(t, "1", doc.Rs.Transactions[0].FITID)
+	require.Equal(t, "Account 8", doc.Rs.Transactions[0].Name)
+	require.Equal(t, "Transfer 40", doc.Rs.Transactions[0].Memo)
+	require.Equal(t, "CREDIT", doc.Rs.Transactions[1].Type)
+	require.Equal(t, "2.50", doc.Rs.Transactions[1].Amount)
+	require.Empty(t, doc.Rs.Transactions[1].Name)
+}
+
+func TestCamt053(t *testing.T) {
+	out := writeStatement(t, FormatCamt053, testStatement())
+
+	var doc struct {
+		XMLName xml.Name `xml:"urn:iso:std:iso:20022:tech:xsd:camt.053.001.02 Document"`
+		Stmt    struct {
+			ID       string        `xml:"Id"`
+			Account  camtAccount   `xml:"Acct"`
+			Balances []camtBalance `xml:"Bal"`
+			Entries  []camtEntry   `xml:"Ntry"`
+		} `xml:"BkToCstmrStmt>Stmt"`
+	}
+	require.NoError(t, xml.Unmarshal(out, &doc))
+
+	require.Equal(t, "STMT-7-20300201000000", doc.Stmt.ID)
+	require.Equal(t, "7", doc.Stmt.Account.ID)
+	require.Equal(t, "alice", doc.Stmt.Account.Owner)
+
+	require.Len(t, doc.Stmt.Balances, 2)
+	require.Equal(t, "OPBD", doc.Stmt.Balances[0].Type)
+	require.Equal(t, camtAmount{Currency: "EUR", Value: "10.00"}, doc.Stmt.Balances[0].Amount)
+	require.Equal(t, camtCredit, doc.Stmt.Balances[0].Indicator)
+	require.Equal(t, "CLBD", doc.Stmt.Balances[1].Type)
+	require.Equal(t, camtAmount{Currency: "EUR", Value: "2.50"}, doc.Stmt.Balances[1].Amount)
+	require.Equal(t, camtDebit, doc.Stmt.Balances[1].Indicator)
+
+	require.Len(t, doc.Stmt.Entries, 2)
+
+	debit := doc.Stmt.Entries[0]
+	require.Equal(t, "15.00", debit.Amount.Value)
+	require.Equal(t, camtDebit, debit.Indicator)
+	require.Equal(t, "2030-01-01T01:00:00Z", debit.BookingDate.DateTime)
+	require.Equal(t, "TRANSFER", debit.Code)
+	require.Equal(t, "40", debit.ServicerRef)
+	require.NotNil(t, debit.Transactions)
+	require.Equal(t, &camtAccountID{ID: "8"}, debit.Transactions.CreditorAccount)
+	require.Nil(t, debit.Transactions.DebtorAccount)
+
+	credit := doc.Stmt.Entries[1]
+	require.Equal(t, "2.50", credit.Amount.Value)
+	require.Equal(t, camtCredit, credit.Indicator)
+	require.Equal(t, "ADJUSTMENT", credit.Code)
+	require.Nil(t, credit.Transactions)
+	require.NotContains(t, string(out), "<DbtrAcct>")
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	_, err := NewWriter(FormatJSON, &bytes.Buffer{}, testAccount(), testTo)
+	require.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, err = NewWriter("pdf", &bytes.Buffer{}, testAccount(), testTo)
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestFormatOf(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatCSV, FormatOFX, FormatCamt053} {
+		got, ok := FormatOf(MediaType(format))
+		require.True(t, ok)
+		require.Equal(t, format, got)
+	}
+
+	_, ok := FormatOf("image/png")
+	require.False(t, ok)
+
+	name := FileName(FormatCamt053, db.AccountStatementTxParams{AccountID: 7, From: testFrom, To: testTo})
+	require.Equal(t, "statement-7-20300101-20300201.xml", name)
+}
@@ -0,0 +1,85 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// xmlStream writes an XML document piece by piece. It keeps the elements left open and the
+// first error, later calls do nothing once one failed.
+type xmlStream struct {
+	enc  *xml.Encoder
+	open []xml.Name
+	err  error
+}
+
+func newXMLStream(out io.Writer) *xmlStream {
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	return &xmlStream{enc: enc}
+}
+
+// token writes a raw token such as a processing instruction
+func (s *xmlStream) token(t xml.Token) {
+	if s.err == nil {
+		s.err = s.enc.EncodeToken(t)
+	}
+}
+
+// header writes a processing instruction on its own line, before the root element
+func (s *xmlStream) header(target string, inst string) {
+	s.token(xml.ProcInst{Target: target, Inst: []byte(inst)})
+	s.token(xml.CharData("\n"))
+}
+
+// start opens an element, it stays open until the matching end
+func (s *xmlStream) start(name string, attrs ...xml.Attr) {
+	start := xml.StartElement{Name: xml.Name{Local: name}, Attr: attrs}
+	s.token(start)
+	s.open = append(s.open, start.Name)
+}
+
+// end closes the innermost open element
+func (s *xmlStream) end() {
+	if len(s.open) == 0 {
+		return
+	}
+
+	name := s.open[len(s.open)-1]
+	s.open = s.open[:len(s.open)-1]
+	s.token(xml.EndElement{Name: name})
+}
+
+// text writes an element holding only text
+func (s *xmlStream) text(name string, value string) {
+	s.element(value, name)
+}
+
+// element writes v as an element named name, or named by v itself when name is empty
+func (s *xmlStream) element(v any, name string) {
+	if s.err != nil {
+		return
+	}
+
+	if name == "" {
+		s.err = s.enc.Encode(v)
+		return
+	}
+	s.err = s.enc.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}})
+}
+
+// flush writes out what the encoder buffered and returns the first error
+func (s *xmlStream) flush() error {
+	if s.err == nil {
+		s.err = s.enc.Flush()
+	}
+	return s.err
+}
+
+// close ends the elements left open and flushes the document
+func (s *xmlStream) close() error {
+	for len(s.open) > 0 {
+		s.end()
+	}
+	return s.flush()
+}
@@ -1,11 +1,51 @@
 package util
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type Config struct {
-	DBDriver     string `mapstructure:"DB_DRIVER"`
-	DBSource     string `mapstructure:"DB_SOURCE"`
-	ServerAdress string `mapstructure:"SERVER_ADDRESS"`
+	DBDriver             string        `mapstructure:"DB_DRIVER"`
+	DBSource             string        `mapstructure:"DB_SOURCE"`
+	ServerAdress         string        `mapstructure:"SERVER_ADDRESS"`
+	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	FXRatesFile          string        `mapstructure:"FX_RATES_FILE"`
+	CurrenciesFile       string        `mapstructure:"CURRENCIES_FILE"`
+	// FeeSchedulesFile holds fee rules that are written to the fee_schedules table on startup
+	FeeSchedulesFile string `mapstructure:"FEE_SCHEDULES_FILE"`
+	// SuspenseAccountsFile holds the suspense account of each currency, written to the suspense_accounts table on startup
+	SuspenseAccountsFile string `mapstructure:"SUSPENSE_ACCOUNTS_FILE"`
+	// AccountProductsFile holds the savings products, written to the account_products table on startup
+	AccountProductsFile string `mapstructure:"ACCOUNT_PRODUCTS_FILE"`
+	// ScheduledTransferInterval is how often due scheduled transfers are polled
+	ScheduledTransferInterval time.Duration `mapstructure:"SCHEDULED_TRANSFER_INTERVAL"`
+	// HoldTTL is how long an authorized transfer reserves funds before it expires
+	HoldTTL time.Duration `mapstructure:"HOLD_TTL"`
+	// HoldExpiryInterval is how often expired holds are released
+	HoldExpiryInterval time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	// Global defaults of the outgoing transfer limits of an account, zero leaves a limit unset
+	TransferDailyCountLimit    int64 `mapstructure:"TRANSFER_DAILY_COUNT_LIMIT"`
+	TransferDailyAmountLimit   int64 `mapstructure:"TRANSFER_DAILY_AMOUNT_LIMIT"`
+	TransferMonthlyCountLimit  int64 `mapstructure:"TRANSFER_MONTHLY_COUNT_LIMIT"`
+	TransferMonthlyAmountLimit int64 `mapstructure:"TRANSFER_MONTHLY_AMOUNT_LIMIT"`
+	// ReconcileInterval is how often the server checks the ledger, zero disables the check
+	ReconcileInterval time.Duration `mapstructure:"RECONCILE_INTERVAL"`
+	// ReconcileChunkSize is how many accounts the ledger check scans per query
+	ReconcileChunkSize int32 `mapstructure:"RECONCILE_CHUNK_SIZE"`
+	// WebhookInterval is how often outbox events are dispatched and due webhook deliveries sent
+	WebhookInterval time.Duration `mapstructure:"WEBHOOK_INTERVAL"`
+	// InterestInterval is how often the interest of finished days is accrued and capitalized
+	InterestInterval time.Duration `mapstructure:"INTEREST_INTERVAL"`
+	// BalanceSnapshotInterval is how often the nightly balance snapshot is checked for
+	BalanceSnapshotInterval time.Duration `mapstructure:"BALANCE_SNAPSHOT_INTERVAL"`
+	// AuditReaders are the users allowed to read the whole audit log, others only read their own changes
+	AuditReaders []string `mapstructure:"AUDIT_READERS"`
+	// Operators are the users allowed to run the back office endpoints, like the exported metrics
+	Operators []string `mapstructure:"OPERATORS"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
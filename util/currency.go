@@ -1,15 +1,9 @@
 package util
 
+import "simplebank/currency"
+
 const (
-	USD = "USD"
-	EUR = "EUR"
-	CAD = "CAD"
+	USD = currency.USD
+	EUR = currency.EUR
+	CAD = currency.CAD
 )
-
-func IsSupported(currency string) bool {
-	switch currency {
-	case USD, EUR, CAD:
-		return true
-	}
-	return false
-}
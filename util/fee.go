@@ -0,0 +1,68 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"simplebank/currency"
+)
+
+// FeeScheduleConfig is a fee rule read from the fee schedules file
+type FeeScheduleConfig struct {
+	Currency string `json:"currency"`
+	// Tier restricts the rule to accounts of that tier, empty applies to every tier
+	Tier    string `json:"tier"`
+	FlatFee int64  `json:"flat_fee"`
+	// PercentageBps is the percentage of the amount in basis points, 150 is 1.5%
+	PercentageBps int32 `json:"percentage_bps"`
+	MinFee        int64 `json:"min_fee"`
+	// MaxFee caps the fee, zero means no cap
+	MaxFee int64 `json:"max_fee"`
+	// FeeAccountID is the house account the fees are paid to, it must hold Currency
+	FeeAccountID int64 `json:"fee_account_id"`
+}
+
+// LoadFeeSchedules reads a JSON array of fee rules, e.g.
+// [{"currency": "USD", "flat_fee": 25, "percentage_bps": 100, "max_fee": 500, "fee_account_id": 1}]
+func LoadFeeSchedules(path string) ([]FeeScheduleConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read fee schedules: %w", err)
+	}
+
+	var schedules []FeeScheduleConfig
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("cannot parse fee schedules: %w", err)
+	}
+
+	seen := make(map[string]bool, len(schedules))
+	for i, schedule := range schedules {
+		if err := schedule.validate(); err != nil {
+			return nil, fmt.Errorf("invalid fee schedule %d: %w", i, err)
+		}
+
+		key := schedule.Currency + "/" + schedule.Tier
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate fee schedule for currency %s and tier %q", schedule.Currency, schedule.Tier)
+		}
+		seen[key] = true
+	}
+
+	return schedules, nil
+}
+
+func (schedule FeeScheduleConfig) validate() error {
+	switch {
+	case !currency.IsSupported(schedule.Currency):
+		return fmt.Errorf("unsupported currency %q", schedule.Currency)
+	case schedule.FlatFee < 0 || schedule.MinFee < 0 || schedule.MaxFee < 0:
+		return fmt.Errorf("fees must not be negative")
+	case schedule.PercentageBps < 0 || schedule.PercentageBps > 10_000:
+		return fmt.Errorf("percentage_bps must be between 0 and 10000")
+	case schedule.MaxFee > 0 && schedule.MaxFee < schedule.MinFee:
+		return fmt.Errorf("max_fee must not be below min_fee")
+	case schedule.FeeAccountID <= 0:
+		return fmt.Errorf("fee_account_id is required")
+	}
+	return nil
+}
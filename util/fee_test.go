@@ -0,0 +1,48 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFeeSchedules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fees.json")
+	err := os.WriteFile(path, []byte(`[
+		{"currency": "USD", "flat_fee": 25, "percentage_bps": 100, "max_fee": 500, "fee_account_id": 1},
+		{"currency": "USD", "tier": "premium", "fee_account_id": 1}
+	]`), 0o600)
+	require.NoError(t, err)
+
+	schedules, err := LoadFeeSchedules(path)
+	require.NoError(t, err)
+	require.Len(t, schedules, 2)
+	require.Equal(t, int32(100), schedules[0].PercentageBps)
+	require.Equal(t, "premium", schedules[1].Tier)
+}
+
+func TestLoadFeeSchedulesInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{"UnsupportedCurrency", `[{"currency": "XXX", "fee_account_id": 1}]`},
+		{"NegativeFee", `[{"currency": "USD", "flat_fee": -1, "fee_account_id": 1}]`},
+		{"PercentageOverWhole", `[{"currency": "USD", "percentage_bps": 10001, "fee_account_id": 1}]`},
+		{"MaxBelowMin", `[{"currency": "USD", "min_fee": 10, "max_fee": 5, "fee_account_id": 1}]`},
+		{"MissingFeeAccount", `[{"currency": "USD"}]`},
+		{"Duplicate", `[{"currency": "USD", "fee_account_id": 1}, {"currency": "USD", "fee_account_id": 2}]`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fees.json")
+			require.NoError(t, os.WriteFile(path, []byte(tc.data), 0o600))
+
+			_, err := LoadFeeSchedules(path)
+			require.Error(t, err)
+		})
+	}
+}
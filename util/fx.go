@@ -0,0 +1,133 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"simplebank/currency"
+	"strings"
+)
+
+// FXRateScale is the fixed-point scale of FX rates, a rate of 1.25 is represented as 125000000
+const FXRateScale int64 = 100_000_000
+
+// ErrFXRateNotFound is returned when no rate is known for a currency pair
+var ErrFXRateNotFound = errors.New("fx rate not found")
+
+// FXRateProvider gives the exchange rate between two currencies
+type FXRateProvider interface {
+	// Rate returns how many units of `to` one unit of `from` buys, scaled by FXRateScale
+	Rate(ctx context.Context, from string, to string) (int64, error)
+}
+
+// StaticFXRateProvider serves rates from a fixed table
+type StaticFXRateProvider struct {
+	rates map[string]int64
+}
+
+// NewStaticFXRateProvider creates a provider from rates keyed by "FROM/TO", scaled by FXRateScale
+func NewStaticFXRateProvider(rates map[string]int64) *StaticFXRateProvider {
+	provider := &StaticFXRateProvider{rates: make(map[string]int64, len(rates))}
+	for pair, rate := range rates {
+		provider.rates[pair] = rate
+	}
+	return provider
+}
+
+// LoadFXRates reads a JSON file of decimal rates keyed by "FROM/TO", e.g. {"USD/EUR": "0.92"}
+func LoadFXRates(path string) (*StaticFXRateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read fx rates: %w", err)
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("cannot parse fx rates: %w", err)
+	}
+
+	rates := make(map[string]int64, len(raw))
+	for pair, value := range raw {
+		from, to, ok := strings.Cut(pair, "/")
+		if !ok || !currency.IsSupported(from) || !currency.IsSupported(to) {
+			return nil, fmt.Errorf("invalid currency pair %q", pair)
+		}
+
+		rate, err := ParseFXRate(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate for %s: %w", pair, err)
+		}
+		rates[pair] = rate
+	}
+
+	return NewStaticFXRateProvider(rates), nil
+}
+
+// Rate returns the rate for the pair, falling back to the inverse of the opposite pair
+func (provider *StaticFXRateProvider) Rate(ctx context.Context, from string, to string) (int64, error) {
+	if from == to {
+		return FXRateScale, nil
+	}
+
+	if rate, ok := provider.rates[from+"/"+to]; ok {
+		return rate, nil
+	}
+
+	if rate, ok := provider.rates[to+"/"+from]; ok && rate > 0 {
+		return roundDiv(new(big.Int).Mul(big.NewInt(FXRateScale), big.NewInt(FXRateScale)), big.NewInt(rate)).Int64(), nil
+	}
+
+	return 0, fmt.Errorf("%w: %s/%s", ErrFXRateNotFound, from, to)
+}
+
+// ParseFXRate parses a positive decimal rate such as "1.0835" into its FXRateScale representation
+func ParseFXRate(value string) (int64, error) {
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok || rat.Sign() <= 0 {
+		return 0, fmt.Errorf("rate must be a positive decimal, got %q", value)
+	}
+
+	scaled := new(big.Rat).Mul(rat, new(big.Rat).SetInt64(FXRateScale))
+	rate := roundDiv(scaled.Num(), scaled.Denom())
+	if !rate.IsInt64() || rate.Sign() <= 0 {
+		return 0, fmt.Errorf("rate %q is out of range", value)
+	}
+	return rate.Int64(), nil
+}
+
+// ConvertAmount converts an amount in the minor units of from into the minor units of to, with a
+// rate scaled by FXRateScale between their major units, rounding half away from zero
+func ConvertAmount(amount int64, rate int64, from currency.Currency, to currency.Currency) (int64, error) {
+	product := new(big.Int).Mul(big.NewInt(amount), big.NewInt(rate))
+	divisor := big.NewInt(FXRateScale)
+
+	// e.g. 1 USD cent is a hundredth of a dollar but a yen has no minor unit
+	exp := to.MinorUnits - from.MinorUnits
+	if exp > 0 {
+		product.Mul(product, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+	} else if exp < 0 {
+		divisor.Mul(divisor, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-exp)), nil))
+	}
+
+	converted := roundDiv(product, divisor)
+	if !converted.IsInt64() {
+		return 0, fmt.Errorf("converted amount overflows: %d %s at rate %d", amount, from.Code, rate)
+	}
+	return converted.Int64(), nil
+}
+
+// roundDiv divides a by a positive b, rounding half away from zero
+func roundDiv(a *big.Int, b *big.Int) *big.Int {
+	quo, rem := new(big.Int).QuoRem(a, b, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(b) >= 0 {
+		if a.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo
+}
@@ -0,0 +1,88 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"simplebank/currency"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFXRate(t *testing.T) {
+	rate, err := ParseFXRate("1.25")
+	require.NoError(t, err)
+	require.Equal(t, int64(125_000_000), rate)
+
+	rate, err = ParseFXRate("0.000000005")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rate)
+
+	_, err = ParseFXRate("-1")
+	require.Error(t, err)
+
+	_, err = ParseFXRate("abc")
+	require.Error(t, err)
+}
+
+func TestConvertAmount(t *testing.T) {
+	registry, err := currency.NewISORegistry(currency.USD, currency.EUR, "JPY", "BHD")
+	require.NoError(t, err)
+	usd, _ := registry.Lookup(currency.USD)
+	eur, _ := registry.Lookup(currency.EUR)
+	jpy, _ := registry.Lookup("JPY")
+	bhd, _ := registry.Lookup("BHD")
+
+	amount, err := ConvertAmount(1000, 92_000_000, usd, eur)
+	require.NoError(t, err)
+	require.Equal(t, int64(920), amount)
+
+	// 3 * 0.5 = 1.5 rounds up
+	amount, err = ConvertAmount(3, 50_000_000, usd, eur)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), amount)
+
+	// 10.00 USD at 150 JPY per dollar is 1500 yen, not 150000
+	amount, err = ConvertAmount(1000, 150*FXRateScale, usd, jpy)
+	require.NoError(t, err)
+	require.Equal(t, int64(1500), amount)
+
+	// 1500 yen at 1/150 dollar per yen is 10.00 USD
+	amount, err = ConvertAmount(1500, FXRateScale/150, jpy, usd)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), amount)
+
+	// 10.00 USD at 0.376 BHD per dollar is 3.760 BHD
+	amount, err = ConvertAmount(1000, 37_600_000, usd, bhd)
+	require.NoError(t, err)
+	require.Equal(t, int64(3760), amount)
+
+	_, err = ConvertAmount(1<<62, 4*FXRateScale, usd, eur)
+	require.Error(t, err)
+}
+
+func TestStaticFXRateProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{"USD/EUR": "0.8"}`), 0o600)
+	require.NoError(t, err)
+
+	provider, err := LoadFXRates(path)
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), USD, EUR)
+	require.NoError(t, err)
+	require.Equal(t, int64(80_000_000), rate)
+
+	// the inverse pair is derived
+	rate, err = provider.Rate(context.Background(), EUR, USD)
+	require.NoError(t, err)
+	require.Equal(t, int64(125_000_000), rate)
+
+	rate, err = provider.Rate(context.Background(), CAD, CAD)
+	require.NoError(t, err)
+	require.Equal(t, FXRateScale, rate)
+
+	_, err = provider.Rate(context.Background(), USD, CAD)
+	require.ErrorIs(t, err, ErrFXRateNotFound)
+}
@@ -0,0 +1,187 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"simplebank/currency"
+	"time"
+)
+
+// Day-count conventions, how much of a year a day of interest counts for
+const (
+	// DayCountACT365 counts every calendar day as 1/365 of a year
+	DayCountACT365 = "ACT/365"
+	// DayCount30360 counts every month as 30 days of a 360 day year
+	DayCount30360 = "30/360"
+)
+
+// Compounding frequencies, how often accrued interest is added to the balance
+const (
+	CompoundingMonthly   = "monthly"
+	CompoundingQuarterly = "quarterly"
+	CompoundingAnnually  = "annually"
+)
+
+// interestBpsScale is the scale of annual rates in basis points, 10000 is 100%
+const interestBpsScale = 10_000
+
+// compoundingMonths is the length in months of each compounding period
+var compoundingMonths = map[string]int{
+	CompoundingMonthly:   1,
+	CompoundingQuarterly: 3,
+	CompoundingAnnually:  12,
+}
+
+// InterestFractionScale is the scale of the fractions of a minor unit DailyInterest carries from day to day.
+// A day of either convention is a whole number of them: 365 and 360 both divide 26280.
+const InterestFractionScale = interestBpsScale * 26_280
+
+// DailyInterest is the interest balance earns on day at the annual rate rateBps, in whole minor units,
+// and the fraction of a minor unit left over, in units of 1/InterestFractionScale. carry is the fraction
+// left over by the day before, so small balances still earn interest as the fractions add up.
+// Only positive balances earn interest, the carry is kept on other days.
+func DailyInterest(balance int64, rateBps int32, dayCount string, day time.Time, carry int64) (interest int64, remainder int64, err error) {
+	days, yearDays, err := dayFraction(dayCount, day)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if balance <= 0 || rateBps <= 0 || days == 0 {
+		return 0, carry, nil
+	}
+
+	num := new(big.Int).Mul(big.NewInt(balance), big.NewInt(int64(rateBps)))
+	num.Mul(num, big.NewInt(days*InterestFractionScale/(interestBpsScale*yearDays)))
+	num.Add(num, big.NewInt(carry))
+
+	quo, rem := new(big.Int).QuoRem(num, big.NewInt(InterestFractionScale), new(big.Int))
+	if !quo.IsInt64() {
+		return 0, 0, fmt.Errorf("interest overflows: %d at %d bps", balance, rateBps)
+	}
+	return quo.Int64(), rem.Int64(), nil
+}
+
+// dayFraction returns the share of a year day counts for as days/yearDays
+func dayFraction(dayCount string, day time.Time) (days int64, yearDays int64, err error) {
+	switch dayCount {
+	case DayCountACT365:
+		return 1, 365, nil
+	case DayCount30360:
+		return days30360(day, day.AddDate(0, 0, 1)), 360, nil
+	}
+	return 0, 0, fmt.Errorf("unknown day count convention %q", dayCount)
+}
+
+// days30360 counts the days from start to end with the 30/360 bond basis, the 31st counts
+// as the 30th, so the last days of February make up for the days it lacks
+func days30360(start time.Time, end time.Time) int64 {
+	d1, d2 := start.Day(), end.Day()
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+
+	return int64(360*(end.Year()-start.Year()) + 30*(int(end.Month())-int(start.Month())) + d2 - d1)
+}
+
+// PeriodEnd returns the last day of the compounding period day is in
+func PeriodEnd(compounding string, day time.Time) (time.Time, error) {
+	months, ok := compoundingMonths[compounding]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown compounding frequency %q", compounding)
+	}
+
+	year, month, _ := day.Date()
+	for int(month)%months != 0 {
+		month++
+	}
+
+	return lastDayOfMonth(year, month), nil
+}
+
+// LastPeriodEnd returns the last day of the latest compounding period ending on or before day.
+// Periods follow the calendar, quarters end in March, June, September and December.
+func LastPeriodEnd(compounding string, day time.Time) (time.Time, error) {
+	months, ok := compoundingMonths[compounding]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown compounding frequency %q", compounding)
+	}
+
+	year, month, _ := day.Date()
+
+	// the month of day only counts once it is over
+	if lastDayOfMonth(year, month).After(day) {
+		month--
+	}
+
+	for int(month)%months != 0 {
+		month--
+	}
+
+	return lastDayOfMonth(year, month), nil
+}
+
+// lastDayOfMonth normalizes months out of range, month 0 is December of the year before
+func lastDayOfMonth(year int, month time.Month) time.Time {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// AccountProductConfig is a savings product read from the account products file
+type AccountProductConfig struct {
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	// RateBps is the annual interest rate in basis points, 425 is 4.25%
+	RateBps     int32  `json:"rate_bps"`
+	DayCount    string `json:"day_count"`
+	Compounding string `json:"compounding"`
+}
+
+// LoadAccountProducts reads a JSON array of savings products, e.g.
+// [{"name": "savings-usd", "currency": "USD", "rate_bps": 425, "day_count": "ACT/365", "compounding": "monthly"}]
+func LoadAccountProducts(path string) ([]AccountProductConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read account products: %w", err)
+	}
+
+	var products []AccountProductConfig
+	if err := json.Unmarshal(data, &products); err != nil {
+		return nil, fmt.Errorf("cannot parse account products: %w", err)
+	}
+
+	seen := make(map[string]bool, len(products))
+	for i, product := range products {
+		if err := product.validate(); err != nil {
+			return nil, fmt.Errorf("invalid account product %d: %w", i, err)
+		}
+
+		if seen[product.Name] {
+			return nil, fmt.Errorf("duplicate account product %q", product.Name)
+		}
+		seen[product.Name] = true
+	}
+
+	return products, nil
+}
+
+func (product AccountProductConfig) validate() error {
+	_, knownCompounding := compoundingMonths[product.Compounding]
+
+	switch {
+	case product.Name == "":
+		return fmt.Errorf("name is required")
+	case !currency.IsSupported(product.Currency):
+		return fmt.Errorf("unsupported currency %q", product.Currency)
+	case product.RateBps < 0:
+		return fmt.Errorf("rate_bps must not be negative")
+	case product.DayCount != DayCountACT365 && product.DayCount != DayCount30360:
+		return fmt.Errorf("unknown day count convention %q", product.DayCount)
+	case !knownCompounding:
+		return fmt.Errorf("unknown compounding frequency %q", product.Compounding)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDailyInterest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		balance  int64
+		rateBps  int32
+		dayCount string
+		day      time.Time
+		interest int64
+	}{
+		{"ACT365", 1_000_000, 425, DayCountACT365, date(2024, time.March, 5), 116},
+		{"FractionCarried", 1825, 1000, DayCountACT365, date(2024, time.March, 5), 0},
+		{"OneAndAHalf", 5475, 1000, DayCountACT365, date(2024, time.March, 5), 1},
+		{"NegativeBalance", -1_000_000, 425, DayCountACT365, date(2024, time.March, 5), 0},
+		{"ZeroRate", 1_000_000, 0, DayCountACT365, date(2024, time.March, 5), 0},
+		{"30360", 3_600_000, 1000, DayCount30360, date(2024, time.March, 5), 1000},
+		{"30360Day31", 3_600_000, 1000, DayCount30360, date(2024, time.January, 31), 1000},
+		{"30360Day30Of31", 3_600_000, 1000, DayCount30360, date(2024, time.January, 30), 0},
+		{"30360EndOfFebruary", 3_600_000, 1000, DayCount30360, date(2023, time.February, 28), 3000},
+		{"30360EndOfLeapFebruary", 3_600_000, 1000, DayCount30360, date(2024, time.February, 29), 2000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interest, _, err := DailyInterest(tc.balance, tc.rateBps, tc.dayCount, tc.day, 0)
+			require.NoError(t, err)
+			require.Equal(t, tc.interest, interest)
+		})
+	}
+}
+
+func TestDailyInterestCarry(t *testing.T) {
+	// 4.25% of 100 is 0.0116 a day, a year of it adds up to 4.25
+	var total, carry int64
+	for day := date(2023, time.January, 1); day.Year() == 2023; day = day.AddDate(0, 0, 1) {
+		interest, remainder, err := DailyInterest(100, 425, DayCountACT365, day, carry)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, remainder, int64(0))
+		require.Less(t, remainder, int64(InterestFractionScale))
+
+		total += interest
+		carry = remainder
+	}
+
+	require.Equal(t, int64(4), total)
+	require.Equal(t, int64(InterestFractionScale/4), carry)
+
+	// days without interest keep the carry
+	interest, remainder, err := DailyInterest(-100, 425, DayCountACT365, date(2024, time.January, 1), carry)
+	require.NoError(t, err)
+	require.Zero(t, interest)
+	require.Equal(t, carry, remainder)
+}
+
+func TestDailyInterestUnknownDayCount(t *testing.T) {
+	_, _, err := DailyInterest(1_000_000, 425, "ACT/ACT", date(2024, time.March, 5), 0)
+	require.Error(t, err)
+}
+
+func TestDays30360SumToThirtyPerMonth(t *testing.T) {
+	for month := time.January; month <= time.December; month++ {
+		var days int64
+		for day := date(2023, month, 1); day.Month() == month; day = day.AddDate(0, 0, 1) {
+			days += days30360(day, day.AddDate(0, 0, 1))
+		}
+		require.Equal(t, int64(30), days, month.String())
+	}
+}
+
+func TestPeriodEnd(t *testing.T) {
+	testCases := []struct {
+		name        string
+		compounding string
+		day         time.Time
+		periodEnd   time.Time
+	}{
+		{"MonthlyMidMonth", CompoundingMonthly, date(2024, time.February, 15), date(2024, time.February, 29)},
+		{"MonthlyMonthEnd", CompoundingMonthly, date(2024, time.March, 31), date(2024, time.March, 31)},
+		{"QuarterlyFirstDay", CompoundingQuarterly, date(2024, time.April, 1), date(2024, time.June, 30)},
+		{"QuarterlyLastDay", CompoundingQuarterly, date(2024, time.September, 30), date(2024, time.September, 30)},
+		{"AnnuallyJanuary", CompoundingAnnually, date(2024, time.January, 1), date(2024, time.December, 31)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			periodEnd, err := PeriodEnd(tc.compounding, tc.day)
+			require.NoError(t, err)
+			require.Equal(t, tc.periodEnd, periodEnd)
+		})
+	}
+
+	_, err := PeriodEnd("weekly", date(2024, time.March, 15))
+	require.Error(t, err)
+}
+
+func TestLastPeriodEnd(t *testing.T) {
+	testCases := []struct {
+		name        string
+		compounding string
+		day         time.Time
+		periodEnd   time.Time
+	}{
+		{"MonthlyMidMonth", CompoundingMonthly, date(2024, time.March, 15), date(2024, time.February, 29)},
+		{"MonthlyMonthEnd", CompoundingMonthly, date(2024, time.March, 31), date(2024, time.March, 31)},
+		{"MonthlyJanuary", CompoundingMonthly, date(2024, time.January, 15), date(2023, time.December, 31)},
+		{"QuarterlyBeforeQuarterEnd", CompoundingQuarterly, date(2024, time.March, 30), date(2023, time.December, 31)},
+		{"QuarterlyAfterQuarterEnd", CompoundingQuarterly, date(2024, time.May, 10), date(2024, time.March, 31)},
+		{"AnnuallyYearEnd", CompoundingAnnually, date(2024, time.December, 31), date(2024, time.December, 31)},
+		{"AnnuallyMidYear", CompoundingAnnually, date(2024, time.June, 1), date(2023, time.December, 31)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			periodEnd, err := LastPeriodEnd(tc.compounding, tc.day)
+			require.NoError(t, err)
+			require.Equal(t, tc.periodEnd, periodEnd)
+		})
+	}
+
+	_, err := LastPeriodEnd("weekly", date(2024, time.March, 15))
+	require.Error(t, err)
+}
+
+func TestLoadAccountProducts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.json")
+	err := os.WriteFile(path, []byte(`[
+		{"name": "savings-usd", "currency": "USD", "rate_bps": 425, "day_count": "ACT/365", "compounding": "monthly"},
+		{"name": "bond-eur", "currency": "EUR", "rate_bps": 300, "day_count": "30/360", "compounding": "quarterly"}
+	]`), 0o600)
+	require.NoError(t, err)
+
+	products, err := LoadAccountProducts(path)
+	require.NoError(t, err)
+	require.Len(t, products, 2)
+	require.Equal(t, "bond-eur", products[1].Name)
+	require.Equal(t, int32(300), products[1].RateBps)
+	require.Equal(t, DayCount30360, products[1].DayCount)
+	require.Equal(t, CompoundingQuarterly, products[1].Compounding)
+}
+
+func TestLoadAccountProductsInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{"MissingName", `[{"currency": "USD", "rate_bps": 425, "day_count": "ACT/365", "compounding": "monthly"}]`},
+		{"UnsupportedCurrency", `[{"name": "a", "currency": "XXX", "rate_bps": 425, "day_count": "ACT/365", "compounding": "monthly"}]`},
+		{"NegativeRate", `[{"name": "a", "currency": "USD", "rate_bps": -1, "day_count": "ACT/365", "compounding": "monthly"}]`},
+		{"UnknownDayCount", `[{"name": "a", "currency": "USD", "rate_bps": 425, "day_count": "ACT/ACT", "compounding": "monthly"}]`},
+		{"UnknownCompounding", `[{"name": "a", "currency": "USD", "rate_bps": 425, "day_count": "ACT/365", "compounding": "daily"}]`},
+		{"Duplicate", `[
+			{"name": "a", "currency": "USD", "rate_bps": 425, "day_count": "ACT/365", "compounding": "monthly"},
+			{"name": "a", "currency": "EUR", "rate_bps": 300, "day_count": "ACT/365", "compounding": "monthly"}
+		]`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "products.json")
+			require.NoError(t, os.WriteFile(path, []byte(tc.data), 0o600))
+
+			_, err := LoadAccountProducts(path)
+			require.Error(t, err)
+		})
+	}
+}
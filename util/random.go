@@ -1,7 +1,9 @@
 package util
 
 import (
+	"fmt"
 	"math/rand"
+	"simplebank/currency"
 	"strings"
 	"time"
 )
@@ -39,7 +41,11 @@ func RandomAmount() int64 {
 }
 
 func RandomCurrency() string {
-	curr := []string{EUR, USD, CAD}
+	curr := currency.Default().Codes()
 	n := len(curr)
 	return curr[rand.Intn(n)]
 }
+
+func RandomEmail() string {
+	return fmt.Sprintf("%s@email.com", RandomString(6))
+}
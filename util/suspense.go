@@ -0,0 +1,44 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"simplebank/currency"
+)
+
+// SuspenseAccountConfig is the suspense account of a currency read from the suspense accounts file
+type SuspenseAccountConfig struct {
+	Currency string `json:"currency"`
+	// AccountID is the house account balancing the adjustments in Currency, it must hold Currency
+	AccountID int64 `json:"account_id"`
+}
+
+// LoadSuspenseAccounts reads a JSON array of suspense accounts, e.g.
+// [{"currency": "USD", "account_id": 1}]
+func LoadSuspenseAccounts(path string) ([]SuspenseAccountConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read suspense accounts: %w", err)
+	}
+
+	var accounts []SuspenseAccountConfig
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, fmt.Errorf("cannot parse suspense accounts: %w", err)
+	}
+
+	seen := make(map[string]bool, len(accounts))
+	for i, acc := range accounts {
+		switch {
+		case !currency.IsSupported(acc.Currency):
+			return nil, fmt.Errorf("invalid suspense account %d: unsupported currency %q", i, acc.Currency)
+		case acc.AccountID <= 0:
+			return nil, fmt.Errorf("invalid suspense account %d: account_id is required", i)
+		case seen[acc.Currency]:
+			return nil, fmt.Errorf("duplicate suspense account for currency %s", acc.Currency)
+		}
+		seen[acc.Currency] = true
+	}
+
+	return accounts, nil
+}
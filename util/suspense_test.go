@@ -0,0 +1,45 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSuspenseAccounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suspense.json")
+	err := os.WriteFile(path, []byte(`[
+		{"currency": "USD", "account_id": 1},
+		{"currency": "EUR", "account_id": 2}
+	]`), 0o600)
+	require.NoError(t, err)
+
+	accounts, err := LoadSuspenseAccounts(path)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Equal(t, "EUR", accounts[1].Currency)
+	require.Equal(t, int64(2), accounts[1].AccountID)
+}
+
+func TestLoadSuspenseAccountsInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{"UnsupportedCurrency", `[{"currency": "XXX", "account_id": 1}]`},
+		{"MissingAccount", `[{"currency": "USD"}]`},
+		{"Duplicate", `[{"currency": "USD", "account_id": 1}, {"currency": "USD", "account_id": 2}]`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "suspense.json")
+			require.NoError(t, os.WriteFile(path, []byte(tc.data), 0o600))
+
+			_, err := LoadSuspenseAccounts(path)
+			require.Error(t, err)
+		})
+	}
+}
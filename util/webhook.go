@@ -0,0 +1,131 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookSignatureHeader carries the signature of a webhook request, as "t=<unix time>,v1=<hex HMAC>"
+const WebhookSignatureHeader = "Webhook-Signature"
+
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// ErrWebhookHostNotAllowed is returned for webhook URLs that point inside the bank's network
+var ErrWebhookHostNotAllowed = errors.New("webhook host is not allowed")
+
+// CheckWebhookURL rejects webhook URLs whose host is a loopback, private or link-local address,
+// or a name that only resolves locally. Other names are checked when they are resolved, see IsPublicAddr.
+func CheckWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrWebhookHostNotAllowed)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !IsPublicAddr(addr) {
+			return fmt.Errorf("%w: %s", ErrWebhookHostNotAllowed, host)
+		}
+		return nil
+	}
+
+	for _, local := range []string{"localhost", "local", "internal"} {
+		if host == local || strings.HasSuffix(host, "."+local) {
+			return fmt.Errorf("%w: %s", ErrWebhookHostNotAllowed, host)
+		}
+	}
+	return nil
+}
+
+// nonPublicPrefixes are special-purpose ranges that netip has no predicate for
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+}
+
+// IsPublicAddr tells if a webhook may be sent to addr, which is not the case for
+// loopback, private, link-local, multicast and unspecified addresses, nor for nonPublicPrefixes
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified()
+}
+
+// NewWebhookSecret returns a random secret to sign the requests of a webhook with
+func NewWebhookSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(key), nil
+}
+
+// SignWebhook returns the signature header of a webhook body sent at timestamp. The HMAC-SHA256
+// covers the timestamp too, so a captured request can't be replayed later with a fresh one.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", unix, webhookMAC(secret, unix, body))
+}
+
+// VerifyWebhook checks the signature header of a webhook body, it must be signed with secret
+// at most tolerance before now
+func VerifyWebhook(secret string, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var unix, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || signature == "" {
+		return fmt.Errorf("%w: malformed header", ErrInvalidWebhookSignature)
+	}
+
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp out of tolerance", ErrInvalidWebhookSignature)
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(webhookMAC(secret, unix, body))) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+func webhookMAC(secret string, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	secret, err := NewWebhookSecret()
+	require.NoError(t, err)
+	require.NotEmpty(t, secret)
+
+	other, err := NewWebhookSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, other)
+
+	body := []byte(`{"type":"transfer.created"}`)
+	sentAt := time.Now()
+	header := SignWebhook(secret, sentAt, body)
+
+	require.NoError(t, VerifyWebhook(secret, header, body, time.Minute, sentAt.Add(time.Second)))
+
+	testCases := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+	}{
+		{"WrongSecret", other, header, body, sentAt},
+		{"TamperedBody", secret, header, []byte(`{"type":"account.created"}`), sentAt},
+		{"Expired", secret, header, body, sentAt.Add(time.Hour)},
+		{"Malformed", secret, "v1=abc", body, sentAt},
+		{"Empty", secret, "", body, sentAt},
+	}
+
+	for _, tc := range testCases {
+		err := VerifyWebhook(tc.secret, tc.header, tc.body, time.Minute, tc.now)
+		require.ErrorIs(t, err, ErrInvalidWebhookSignature, tc.name)
+	}
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	allowed := []string{
+		"https://example.com/hooks",
+		"http://93.184.216.34:8080/hooks",
+		"https://[2606:2800:220:1:248:1893:25c8:1946]/hooks",
+	}
+	for _, rawURL := range allowed {
+		require.NoError(t, CheckWebhookURL(rawURL), rawURL)
+	}
+
+	refused := []string{
+		"http://localhost:8081/hooks",
+		"http://api.localhost/hooks",
+		"http://db.internal/hooks",
+		"http://127.0.0.1/hooks",
+		"http://10.0.0.1/hooks",
+		"http://192.168.1.1/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hooks",
+		"http://[fe80::1]/hooks",
+		"http://[::ffff:127.0.0.1]/hooks",
+		"http://0.0.0.0/hooks",
+		"http://100.64.0.1/hooks",
+		"http://100.127.255.254/hooks",
+		"http://192.0.0.170/hooks",
+		"http://198.18.0.1/hooks",
+		"http://198.19.255.255/hooks",
+		"http://[::ffff:100.64.0.1]/hooks",
+	}
+	for _, rawURL := range refused {
+		require.ErrorIs(t, CheckWebhookURL(rawURL), ErrWebhookHostNotAllowed, rawURL)
+	}
+}
@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"log"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+// BalanceSnapshotWorker snapshots the balance of every account as of each UTC midnight
+type BalanceSnapshotWorker struct {
+	store     db.Store
+	interval  time.Duration
+	chunkSize int32
+	// taken is the time of the last snapshot taken
+	taken time.Time
+}
+
+// NewBalanceSnapshotWorker creates a worker polling every interval, chunkSize accounts at a time
+func NewBalanceSnapshotWorker(store db.Store, interval time.Duration, chunkSize int32) *BalanceSnapshotWorker {
+	return &BalanceSnapshotWorker{
+		store:     store,
+		interval:  interval,
+		chunkSize: chunkSize,
+	}
+}
+
+// Start takes the snapshot of the last midnight every interval, once it settled, until ctx is cancelled
+func (worker *BalanceSnapshotWorker) Start(ctx context.Context) {
+	poll(ctx, worker.interval, func(ctx context.Context, now time.Time) {
+		worker.snapshot(ctx, now)
+	})
+}
+
+// snapshot takes the snapshot of the last midnight before now unless it was taken already.
+// The transactions still open since before midnight put it off to the next poll.
+func (worker *BalanceSnapshotWorker) snapshot(ctx context.Context, now time.Time) {
+	takenAt := now.UTC().Truncate(24 * time.Hour)
+	if takenAt.Equal(worker.taken) {
+		return
+	}
+
+	res, err := worker.store.SnapshotBalances(ctx, db.SnapshotBalancesParams{
+		TakenAt:   takenAt,
+		ChunkSize: worker.chunkSize,
+	})
+	if err != nil {
+		log.Println("cannot snapshot balances: ", err)
+		return
+	}
+
+	worker.taken = takenAt
+	log.Printf("snapshotted the balances of %d accounts as of %s", res.AccountsSnapshotted, takenAt.Format(time.RFC3339))
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	worker := NewBalanceSnapshotWorker(store, time.Minute, 100)
+
+	midnight := time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)
+	yesterday := midnight.AddDate(0, 0, -1)
+
+	gomock.InOrder(
+		store.EXPECT().
+			SnapshotBalances(gomock.Any(), gomock.Eq(db.SnapshotBalancesParams{TakenAt: yesterday, ChunkSize: 100})).
+			Times(1).
+			Return(db.SnapshotBalancesResult{TakenAt: yesterday, AccountsSnapshotted: 3}, nil),
+		store.EXPECT().
+			SnapshotBalances(gomock.Any(), gomock.Eq(db.SnapshotBalancesParams{TakenAt: midnight, ChunkSize: 100})).
+			Times(1).
+			Return(db.SnapshotBalancesResult{TakenAt: midnight}, db.ErrSnapshotNotSettled),
+		store.EXPECT().
+			SnapshotBalances(gomock.Any(), gomock.Eq(db.SnapshotBalancesParams{TakenAt: midnight, ChunkSize: 100})).
+			Times(1).
+			Return(db.SnapshotBalancesResult{}, sql.ErrConnDone),
+		store.EXPECT().
+			SnapshotBalances(gomock.Any(), gomock.Eq(db.SnapshotBalancesParams{TakenAt: midnight, ChunkSize: 100})).
+			Times(1).
+			Return(db.SnapshotBalancesResult{TakenAt: midnight, AccountsSnapshotted: 3}, nil),
+	)
+
+	worker.snapshot(context.Background(), yesterday.Add(23*time.Hour))
+	require.Equal(t, yesterday, worker.taken)
+
+	// taken already
+	worker.snapshot(context.Background(), yesterday.Add(23*time.Hour+30*time.Minute))
+
+	// a transaction open since before midnight puts the snapshot off
+	worker.snapshot(context.Background(), midnight.Add(time.Minute))
+	require.Equal(t, yesterday, worker.taken)
+
+	// a failed snapshot is retried on the next poll
+	worker.snapshot(context.Background(), midnight.Add(2*time.Minute))
+	require.Equal(t, yesterday, worker.taken)
+
+	worker.snapshot(context.Background(), midnight.Add(3*time.Minute))
+	require.Equal(t, midnight, worker.taken)
+}
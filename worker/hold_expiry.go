@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+// HoldExpiryWorker releases the holds that were neither captured nor voided before they expired
+type HoldExpiryWorker struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewHoldExpiryWorker creates a worker polling every interval
+func NewHoldExpiryWorker(store db.Store, interval time.Duration) *HoldExpiryWorker {
+	return &HoldExpiryWorker{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start expires the due holds every interval until ctx is cancelled
+func (worker *HoldExpiryWorker) Start(ctx context.Context) {
+	poll(ctx, worker.interval, func(ctx context.Context, now time.Time) {
+		worker.expireDue(ctx, now)
+	})
+}
+
+// expireDue releases the holds expired at now one by one and returns how many were released
+func (worker *HoldExpiryWorker) expireDue(ctx context.Context, now time.Time) int {
+	count := 0
+
+	for {
+		hold, err := worker.store.ExpireHoldTx(ctx, now)
+		if err != nil {
+			if !errors.Is(err, db.ErrNoHoldExpired) {
+				log.Println("cannot expire hold: ", err)
+			}
+			return count
+		}
+
+		count++
+		log.Printf("hold [%d] expired, released %d", hold.ID, hold.Amount)
+	}
+}
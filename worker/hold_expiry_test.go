@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpireDue(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		count      int
+	}{
+		{
+			name: "ExpiresUntilNoneLeft",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						ExpireHoldTx(gomock.Any(), gomock.Eq(now)).
+						Times(3).
+						Return(db.Hold{Status: db.HoldStatusExpired}, nil),
+					store.EXPECT().
+						ExpireHoldTx(gomock.Any(), gomock.Eq(now)).
+						Times(1).
+						Return(db.Hold{}, db.ErrNoHoldExpired),
+				)
+			},
+			count: 3,
+		},
+		{
+			name: "StopsOnError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ExpireHoldTx(gomock.Any(), gomock.Eq(now)).
+					Times(1).
+					Return(db.Hold{}, sql.ErrConnDone)
+			},
+			count: 0,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			worker := NewHoldExpiryWorker(store, time.Minute)
+			require.Equal(t, tc.count, worker.expireDue(context.Background(), now))
+		})
+	}
+}
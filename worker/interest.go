@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"log"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+// InterestWorker accrues the daily interest of the savings accounts and capitalizes it
+// at the end of their compounding periods
+type InterestWorker struct {
+	store     db.Store
+	interval  time.Duration
+	chunkSize int32
+}
+
+// NewInterestWorker creates a worker polling every interval, chunkSize savings accounts at a time
+func NewInterestWorker(store db.Store, interval time.Duration, chunkSize int32) *InterestWorker {
+	return &InterestWorker{
+		store:     store,
+		interval:  interval,
+		chunkSize: chunkSize,
+	}
+}
+
+// Start accrues and capitalizes the interest of the days over every interval until ctx is cancelled
+func (worker *InterestWorker) Start(ctx context.Context) {
+	poll(ctx, worker.interval, func(ctx context.Context, now time.Time) {
+		worker.run(ctx, now)
+	})
+}
+
+// run accrues the interest of every day before the UTC day of now, then capitalizes the periods
+// those days complete. Nothing is capitalized when accruing fails, so no period is posted short.
+func (worker *InterestWorker) run(ctx context.Context, now time.Time) {
+	through := now.UTC().AddDate(0, 0, -1)
+
+	accrued, err := worker.store.AccrueInterest(ctx, db.AccrueInterestParams{
+		Through:   through,
+		ChunkSize: worker.chunkSize,
+	})
+	if err != nil {
+		log.Println("cannot accrue interest: ", err)
+		return
+	}
+
+	if accrued.DaysAccrued > 0 {
+		log.Printf("accrued %d days of interest over %d accounts", accrued.DaysAccrued, accrued.AccountsScanned)
+	}
+
+	capitalized, err := worker.store.CapitalizeInterest(ctx, db.CapitalizeInterestParams{
+		Through:   through,
+		ChunkSize: worker.chunkSize,
+	})
+	if err != nil {
+		log.Println("cannot capitalize interest: ", err)
+		return
+	}
+
+	if capitalized.AccountsCapitalized > 0 {
+		log.Printf("capitalized %d accruals on %d accounts", capitalized.AccrualsCapitalized, capitalized.AccountsCapitalized)
+	}
+}
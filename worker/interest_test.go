@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestInterestRun(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 0, 30, 0, 0, time.UTC)
+	yesterday := time.Date(2024, time.February, 29, 0, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "AccruesThenCapitalizes",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						AccrueInterest(gomock.Any(), gomock.Eq(db.AccrueInterestParams{Through: yesterday, ChunkSize: 100})).
+						Times(1).
+						Return(db.AccrueInterestResult{AccountsScanned: 2, DaysAccrued: 2}, nil),
+					store.EXPECT().
+						CapitalizeInterest(gomock.Any(), gomock.Eq(db.CapitalizeInterestParams{Through: yesterday, ChunkSize: 100})).
+						Times(1).
+						Return(db.CapitalizeInterestResult{AccountsScanned: 2, AccountsCapitalized: 2, AccrualsCapitalized: 58}, nil),
+				)
+			},
+		},
+		{
+			name: "AccrueError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AccrueInterest(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccrueInterestResult{}, sql.ErrConnDone)
+				store.EXPECT().
+					CapitalizeInterest(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			worker := NewInterestWorker(store, time.Minute, 100)
+			worker.run(context.Background(), now)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package worker
+
+import (
+	"context"
+	"time"
+)
+
+// poll calls run right away and then every interval until ctx is cancelled
+func poll(ctx context.Context, interval time.Duration, run func(ctx context.Context, now time.Time)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
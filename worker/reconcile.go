@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"expvar"
+	"log"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+// ledgerDiscrepancies exports the discrepancies found by the last ledger check, -1 until one succeeds
+var ledgerDiscrepancies = expvar.NewInt("ledger_discrepancies")
+
+func init() {
+	ledgerDiscrepancies.Set(-1)
+}
+
+// ReconcileWorker checks the ledger invariants periodically and reports what it finds
+type ReconcileWorker struct {
+	store     db.Store
+	interval  time.Duration
+	chunkSize int32
+}
+
+// NewReconcileWorker creates a worker checking the ledger every interval, chunkSize accounts at a time
+func NewReconcileWorker(store db.Store, interval time.Duration, chunkSize int32) *ReconcileWorker {
+	return &ReconcileWorker{
+		store:     store,
+		interval:  interval,
+		chunkSize: chunkSize,
+	}
+}
+
+// Start checks the ledger every interval until ctx is cancelled
+func (worker *ReconcileWorker) Start(ctx context.Context) {
+	poll(ctx, worker.interval, func(ctx context.Context, now time.Time) {
+		worker.reconcile(ctx)
+	})
+}
+
+// reconcile runs one check, logs every discrepancy and exports how many were found
+func (worker *ReconcileWorker) reconcile(ctx context.Context) {
+	res, err := worker.store.ReconcileLedger(ctx, db.ReconcileLedgerParams{
+		ChunkSize: worker.chunkSize,
+	})
+	if err != nil {
+		log.Println("cannot reconcile ledger: ", err)
+		return
+	}
+
+	for _, discrepancy := range res.Discrepancies {
+		log.Printf("ledger discrepancy %s: account [%d] transfer [%d] expected %d, got %d",
+			discrepancy.Kind, discrepancy.AccountID, discrepancy.TransferID, discrepancy.Expected, discrepancy.Actual)
+	}
+
+	ledgerDiscrepancies.Set(int64(len(res.Discrepancies)))
+}
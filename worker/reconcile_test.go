@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	worker := NewReconcileWorker(store, time.Minute, 100)
+
+	arg := db.ReconcileLedgerParams{ChunkSize: 100}
+	gomock.InOrder(
+		store.EXPECT().
+			ReconcileLedger(gomock.Any(), gomock.Eq(arg)).
+			Times(1).
+			Return(db.ReconcileLedgerResult{
+				AccountsScanned: 3,
+				Discrepancies: []db.LedgerDiscrepancy{
+					{Kind: db.DiscrepancyBalance, AccountID: 1, Expected: 10, Actual: 20},
+					{Kind: db.DiscrepancyDebitEntries, AccountID: 2, TransferID: 7, Expected: 1, Actual: 0},
+				},
+			}, nil),
+		store.EXPECT().
+			ReconcileLedger(gomock.Any(), gomock.Eq(arg)).
+			Times(1).
+			Return(db.ReconcileLedgerResult{}, sql.ErrConnDone),
+	)
+
+	worker.reconcile(context.Background())
+	require.Equal(t, int64(2), ledgerDiscrepancies.Value())
+
+	// a failed check keeps the last count
+	worker.reconcile(context.Background())
+	require.Equal(t, int64(2), ledgerDiscrepancies.Value())
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	db "simplebank/db/sqlc"
+	"time"
+)
+
+// ScheduledTransferWorker polls the store for due scheduled transfers and executes them
+type ScheduledTransferWorker struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewScheduledTransferWorker creates a worker polling every interval
+func NewScheduledTransferWorker(store db.Store, interval time.Duration) *ScheduledTransferWorker {
+	return &ScheduledTransferWorker{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start runs the due transfers every interval until ctx is cancelled
+func (worker *ScheduledTransferWorker) Start(ctx context.Context) {
+	poll(ctx, worker.interval, func(ctx context.Context, now time.Time) {
+		worker.runDue(ctx, now)
+	})
+}
+
+// runDue executes the transfers due at now one by one and returns how many ran.
+// It stops at the first unexpected error, the failed schedule is picked again on the next tick.
+func (worker *ScheduledTransferWorker) runDue(ctx context.Context, now time.Time) int {
+	count := 0
+
+	for {
+		res, err := worker.store.ExecuteScheduledTransferTx(ctx, now)
+		if err != nil {
+			if !errors.Is(err, db.ErrNoScheduledTransferDue) {
+				log.Println("cannot execute scheduled transfer: ", err)
+			}
+			return count
+		}
+
+		count++
+		log.Printf("scheduled transfer [%d] attempt %d %s", res.ScheduledTransfer.ID, res.Run.Attempt, res.Run.Status)
+	}
+}
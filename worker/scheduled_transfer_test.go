@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDue(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		count      int
+	}{
+		{
+			name: "RunsUntilNothingDue",
+			buildStubs: func(store *mockdb.MockStore) {
+				res := db.ExecuteScheduledTransferTxResult{
+					Run: db.ScheduledTransferRun{Attempt: 1, Status: db.RunStatusSucceeded},
+				}
+				gomock.InOrder(
+					store.EXPECT().
+						ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(now)).
+						Times(2).
+						Return(res, nil),
+					store.EXPECT().
+						ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(now)).
+						Times(1).
+						Return(db.ExecuteScheduledTransferTxResult{}, db.ErrNoScheduledTransferDue),
+				)
+			},
+			count: 2,
+		},
+		{
+			name: "StopsOnError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(now)).
+					Times(1).
+					Return(db.ExecuteScheduledTransferTxResult{}, sql.ErrConnDone)
+			},
+			count: 0,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			worker := NewScheduledTransferWorker(store, time.Minute)
+			require.Equal(t, tc.count, worker.runDue(context.Background(), now))
+		})
+	}
+}
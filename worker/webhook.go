@@ -0,0 +1,208 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// webhookTimeout bounds a delivery request, it must stay well under db.WebhookDeliveryLease
+	webhookTimeout = 10 * time.Second
+	// maxWebhookResponse is how much of a response is read before the connection is reused
+	maxWebhookResponse = 64 << 10
+)
+
+// Headers of a webhook request besides the signature
+const (
+	webhookEventIDHeader   = "Webhook-Id"
+	webhookEventTypeHeader = "Webhook-Event"
+)
+
+// webhookEvent is the body of a webhook request, Data is the payload of the outbox event
+type webhookEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookWorker fans the outbox events out to the subscribed webhooks and delivers them
+type WebhookWorker struct {
+	store    db.Store
+	client   *http.Client
+	interval time.Duration
+}
+
+// NewWebhookWorker creates a worker polling every interval
+func NewWebhookWorker(store db.Store, interval time.Duration) *WebhookWorker {
+	return &WebhookWorker{
+		store:    store,
+		client:   newWebhookClient(util.IsPublicAddr),
+		interval: interval,
+	}
+}
+
+// newWebhookClient creates the client deliveries are sent with. It only connects to the addresses
+// allowed by allow, checked once the host is resolved so a DNS answer can't point it inside the
+// network, and it doesn't follow redirects, a redirect is a failed delivery.
+func newWebhookClient(allow func(addr netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+
+			if !allow(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", util.ErrWebhookHostNotAllowed, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   webhookTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Start dispatches the outbox and delivers the due events every interval until ctx is cancelled
+func (worker *WebhookWorker) Start(ctx context.Context) {
+	poll(ctx, worker.interval, func(ctx context.Context, now time.Time) {
+		worker.dispatch(ctx)
+		worker.deliverDue(ctx, now)
+	})
+}
+
+// dispatch fans out the outbox until it is drained and returns how many events it dispatched
+func (worker *WebhookWorker) dispatch(ctx context.Context) int {
+	total := 0
+
+	for {
+		count, err := worker.store.DispatchOutboxTx(ctx, db.DefaultOutboxBatchSize)
+		if err != nil {
+			log.Println("cannot dispatch outbox: ", err)
+			return total
+		}
+
+		total += count
+		if count < db.DefaultOutboxBatchSize {
+			return total
+		}
+	}
+}
+
+// deliverDue sends the deliveries due at now one by one and returns how many were attempted.
+// It stops at the first store error, leased deliveries are picked again once their lease ends.
+func (worker *WebhookWorker) deliverDue(ctx context.Context, now time.Time) int {
+	count := 0
+
+	for {
+		delivery, err := worker.store.ClaimDueWebhookDelivery(ctx, db.ClaimDueWebhookDeliveryParams{
+			LeaseUntil: time.Now().Add(db.WebhookDeliveryLease),
+			Now:        now,
+		})
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				log.Println("cannot claim webhook delivery: ", err)
+			}
+			return count
+		}
+
+		deliveryErr := worker.deliver(ctx, delivery)
+		next := nextWebhookDelivery(delivery, deliveryErr, time.Now())
+
+		if _, err := worker.store.UpdateWebhookDelivery(ctx, next); err != nil {
+			log.Println("cannot update webhook delivery: ", err)
+			return count
+		}
+
+		count++
+		if next.Status == db.WebhookDeliveryDead {
+			log.Printf("webhook delivery [%d] dead after %d attempts: %s", delivery.ID, delivery.Attempts, next.LastError.String)
+		}
+	}
+}
+
+// deliver posts the event of a delivery to its webhook, signed with the webhook's secret.
+// Any response other than 2xx is a failure.
+func (worker *WebhookWorker) deliver(ctx context.Context, delivery db.ClaimDueWebhookDeliveryRow) error {
+	body, err := json.Marshal(webhookEvent{
+		ID:        delivery.EventID,
+		Type:      delivery.EventType,
+		CreatedAt: delivery.EventCreatedAt,
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventIDHeader, strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set(webhookEventTypeHeader, delivery.EventType)
+	req.Header.Set(util.WebhookSignatureHeader, util.SignWebhook(delivery.Secret, time.Now(), body))
+
+	resp, err := worker.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponse))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// nextWebhookDelivery records the outcome of an attempt: delivered, retried with backoff,
+// or dead-lettered once it ran out of attempts
+func nextWebhookDelivery(delivery db.ClaimDueWebhookDeliveryRow, deliveryErr error, now time.Time) db.UpdateWebhookDeliveryParams {
+	next := db.UpdateWebhookDeliveryParams{
+		ID:            delivery.ID,
+		Status:        db.WebhookDeliveryDelivered,
+		NextAttemptAt: now,
+	}
+
+	switch {
+	case deliveryErr == nil:
+		next.DeliveredAt = sql.NullTime{Time: now, Valid: true}
+	case delivery.Attempts < db.MaxWebhookAttempts:
+		next.Status = db.WebhookDeliveryPending
+		next.NextAttemptAt = now.Add(db.WebhookRetryBackoff(delivery.Attempts))
+		next.LastError = sql.NullString{String: deliveryErr.Error(), Valid: true}
+	default:
+		next.Status = db.WebhookDeliveryDead
+		next.LastError = sql.NullString{String: deliveryErr.Error(), Valid: true}
+	}
+
+	return next
+}
@@ -0,0 +1,201 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/util"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookReceiver is a local endpoint that checks signatures and answers with status
+type webhookReceiver struct {
+	t        *testing.T
+	secret   string
+	status   int
+	received []webhookEvent
+}
+
+func (receiver *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	require.NoError(receiver.t, err)
+
+	err = util.VerifyWebhook(receiver.secret, r.Header.Get(util.WebhookSignatureHeader), body, time.Minute, time.Now())
+	require.NoError(receiver.t, err)
+
+	var event webhookEvent
+	require.NoError(receiver.t, json.Unmarshal(body, &event))
+	require.Equal(receiver.t, event.Type, r.Header.Get(webhookEventTypeHeader))
+
+	receiver.received = append(receiver.received, event)
+	if receiver.status >= 300 && receiver.status < 400 {
+		w.Header().Set("Location", "/redirected")
+	}
+	w.WriteHeader(receiver.status)
+}
+
+func TestDeliverDue(t *testing.T) {
+	now := time.Now()
+	secret, err := util.NewWebhookSecret()
+	require.NoError(t, err)
+
+	delivery := db.ClaimDueWebhookDeliveryRow{
+		ID:             3,
+		WebhookID:      2,
+		EventID:        1,
+		Attempts:       1,
+		Secret:         secret,
+		EventType:      db.EventTransferCreated,
+		Payload:        json.RawMessage(`{"id":9}`),
+		EventCreatedAt: now.Add(-time.Second).UTC(),
+	}
+
+	testCases := []struct {
+		name          string
+		status        int
+		attempts      int32
+		checkDelivery func(t *testing.T, next db.UpdateWebhookDeliveryParams)
+	}{
+		{
+			name:     "Delivered",
+			status:   http.StatusNoContent,
+			attempts: 1,
+			checkDelivery: func(t *testing.T, next db.UpdateWebhookDeliveryParams) {
+				require.Equal(t, db.WebhookDeliveryDelivered, next.Status)
+				require.True(t, next.DeliveredAt.Valid)
+				require.False(t, next.LastError.Valid)
+			},
+		},
+		{
+			name:     "Retried",
+			status:   http.StatusInternalServerError,
+			attempts: 3,
+			checkDelivery: func(t *testing.T, next db.UpdateWebhookDeliveryParams) {
+				require.Equal(t, db.WebhookDeliveryPending, next.Status)
+				require.WithinDuration(t, time.Now().Add(db.WebhookRetryBackoff(3)), next.NextAttemptAt, time.Second)
+				require.Contains(t, next.LastError.String, "500")
+			},
+		},
+		{
+			name:     "RedirectNotFollowed",
+			status:   http.StatusFound,
+			attempts: 1,
+			checkDelivery: func(t *testing.T, next db.UpdateWebhookDeliveryParams) {
+				require.Equal(t, db.WebhookDeliveryPending, next.Status)
+				require.Contains(t, next.LastError.String, "302")
+			},
+		},
+		{
+			name:     "DeadLettered",
+			status:   http.StatusGone,
+			attempts: db.MaxWebhookAttempts,
+			checkDelivery: func(t *testing.T, next db.UpdateWebhookDeliveryParams) {
+				require.Equal(t, db.WebhookDeliveryDead, next.Status)
+				require.Contains(t, next.LastError.String, "410")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			receiver := &webhookReceiver{t: t, secret: secret, status: tc.status}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			claimed := delivery
+			claimed.Url = server.URL
+			claimed.Attempts = tc.attempts
+
+			store := mockdb.NewMockStore(ctrl)
+			gomock.InOrder(
+				store.EXPECT().
+					ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ClaimDueWebhookDeliveryParams) (db.ClaimDueWebhookDeliveryRow, error) {
+						require.Equal(t, now, arg.Now)
+						require.True(t, arg.LeaseUntil.After(now))
+						return claimed, nil
+					}),
+				store.EXPECT().
+					UpdateWebhookDelivery(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+						require.Equal(t, delivery.ID, arg.ID)
+						tc.checkDelivery(t, arg)
+						return db.WebhookDelivery{ID: arg.ID, Status: arg.Status}, nil
+					}),
+				store.EXPECT().
+					ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ClaimDueWebhookDeliveryRow{}, sql.ErrNoRows),
+			)
+
+			worker := NewWebhookWorker(store, time.Minute)
+			// the receiver listens on loopback, which real deliveries refuse
+			worker.client = newWebhookClient(func(netip.Addr) bool { return true })
+			require.Equal(t, 1, worker.deliverDue(context.Background(), now))
+
+			require.Len(t, receiver.received, 1)
+			require.Equal(t, webhookEvent{
+				ID:        delivery.EventID,
+				Type:      delivery.EventType,
+				CreatedAt: delivery.EventCreatedAt,
+				Data:      delivery.Payload,
+			}, receiver.received[0])
+		})
+	}
+}
+
+func TestDeliverRefusesLocalAddresses(t *testing.T) {
+	secret, err := util.NewWebhookSecret()
+	require.NoError(t, err)
+
+	receiver := &webhookReceiver{t: t, secret: secret, status: http.StatusNoContent}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	worker := NewWebhookWorker(nil, time.Minute)
+	err = worker.deliver(context.Background(), db.ClaimDueWebhookDeliveryRow{
+		ID:        3,
+		Url:       server.URL,
+		Secret:    secret,
+		EventType: db.EventTransferCreated,
+		Payload:   json.RawMessage(`{"id":9}`),
+	})
+	require.ErrorIs(t, err, util.ErrWebhookHostNotAllowed)
+	require.Empty(t, receiver.received)
+}
+
+func TestDispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().DispatchOutboxTx(gomock.Any(), gomock.Eq(int32(db.DefaultOutboxBatchSize))).Times(2).Return(db.DefaultOutboxBatchSize, nil),
+		store.EXPECT().DispatchOutboxTx(gomock.Any(), gomock.Any()).Times(1).Return(3, nil),
+	)
+
+	worker := NewWebhookWorker(store, time.Minute)
+	require.Equal(t, 2*db.DefaultOutboxBatchSize+3, worker.dispatch(context.Background()))
+
+	// a store error stops the dispatch until the next tick
+	store.EXPECT().DispatchOutboxTx(gomock.Any(), gomock.Any()).Times(1).Return(0, errors.New("connection reset"))
+	require.Zero(t, worker.dispatch(context.Background()))
+}